@@ -0,0 +1,104 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CanonicalForm parses the given version and reports whether it is already spelled in the normalized form
+// returned by String. If it isn't, the reason lists what differs, separated by "; ",
+// e.g. "v1.0alpha1" gives "1.0a1", false and "has leading v; uses 'alpha' instead of 'a'".
+// For a malformed version, the canonical form is empty and the reason is the parse error.
+func CanonicalForm(s string) (canonical string, ok bool, reason string) {
+	v, groups, err := ParseDetailed(s)
+	if err != nil {
+		return "", false, err.Error()
+	}
+
+	canonical = v.String()
+	if s == canonical {
+		return canonical, true, ""
+	}
+
+	reasons := canonicalFormReasons(s, groups)
+	if len(reasons) == 0 {
+		reasons = append(reasons, fmt.Sprintf("is not normalized as %s", canonical))
+	}
+	return canonical, false, strings.Join(reasons, "; ")
+}
+
+// canonicalFormReasons returns why the given valid version differs from its normalized form
+// using the groups returned by ParseDetailed.
+func canonicalFormReasons(s string, groups map[string]string) []string {
+	var reasons []string
+	add := func(format string, a ...interface{}) {
+		reasons = append(reasons, fmt.Sprintf(format, a...))
+	}
+
+	public := strings.TrimSpace(s)
+	if public != s {
+		add("has surrounding whitespace")
+	}
+	if strings.HasPrefix(public, "v") || strings.HasPrefix(public, "V") {
+		add("has leading v")
+		public = public[1:]
+	}
+
+	local := groups["local"]
+	if local != "" {
+		public = strings.TrimSuffix(public, "+"+local)
+	}
+	if public != strings.ToLower(public) {
+		add("uses uppercase letters")
+	}
+	if local != strings.ToLower(local) {
+		add("has uppercase local")
+	}
+
+	if epoch := groups["epoch"]; epoch != "" && strings.Trim(epoch, "0") == "" {
+		add("has explicit zero epoch")
+	}
+	numbers := strings.Split(groups["release"], ".")
+	numbers = append(numbers, groups["epoch"], groups["pre_n"], groups["post_n1"], groups["post_n2"], groups["dev_n"])
+	for _, n := range numbers {
+		if len(n) > 1 && n[0] == '0' {
+			add("has leading zeros")
+			break
+		}
+	}
+
+	if pre := strings.ToLower(groups["pre"]); pre != "" {
+		label := strings.ToLower(groups["pre_l"])
+		if normalized := preReleaseAliases[label]; label != normalized {
+			add("uses '%s' instead of '%s'", label, normalized)
+		}
+		if groups["pre_n"] == "" {
+			add("omits pre-release number")
+		} else if pre != label+groups["pre_n"] {
+			add("has separators in pre-release")
+		}
+	}
+
+	if n := groups["post_n1"]; n != "" {
+		add("uses '-%s' instead of '.post%s'", n, n)
+	} else if post := strings.ToLower(groups["post"]); post != "" {
+		label := strings.ToLower(groups["post_l"])
+		if normalized := postReleaseAliases[label]; label != normalized {
+			add("uses '%s' instead of '%s'", label, normalized)
+		}
+		if groups["post_n2"] == "" {
+			add("omits post-release number")
+		} else if post != "."+label+groups["post_n2"] {
+			add("has non-canonical separators in post-release")
+		}
+	}
+
+	if dev := strings.ToLower(groups["dev"]); dev != "" {
+		if groups["dev_n"] == "" {
+			add("omits development release number")
+		} else if dev != ".dev"+groups["dev_n"] {
+			add("has non-canonical separators in development release")
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,43 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalForm(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       string
+		wantOk     bool
+		wantReason string
+	}{
+		{"1.0", "1.0", true, ""},
+		{"1!2.0rc1.post2.dev3+ubuntu.1", "1!2.0rc1.post2.dev3+ubuntu.1", true, ""},
+		{"v1.0", "1.0", false, "has leading v"},
+		{" 1.0\n", "1.0", false, "has surrounding whitespace"},
+		{"1.0alpha1", "1.0a1", false, "uses 'alpha' instead of 'a'"},
+		{"1.0C1", "1.0rc1", false, "uses uppercase letters; uses 'c' instead of 'rc'"},
+		{"1.0+ABC", "1.0+abc", false, "has uppercase local"},
+		{"01.02", "1.2", false, "has leading zeros"},
+		{"0!1.0", "1.0", false, "has explicit zero epoch"},
+		{"1.0-a.1", "1.0a1", false, "has separators in pre-release"},
+		{"1.0rc", "1.0rc0", false, "omits pre-release number"},
+		{"1.0-1", "1.0.post1", false, "uses '-1' instead of '.post1'"},
+		{"1.0rev2", "1.0.post2", false, "uses 'rev' instead of 'post'; has non-canonical separators in post-release"},
+		{"1.0.post", "1.0.post0", false, "omits post-release number"},
+		{"1.0-dev_1", "1.0.dev1", false, "has non-canonical separators in development release"},
+		{"1.0.dev", "1.0.dev0", false, "omits development release number"},
+		{"V01.0Beta", "1.0b0", false, "has leading v; uses uppercase letters; has leading zeros; uses 'beta' instead of 'b'; omits pre-release number"},
+		{"foo", "", false, "malformed version: foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok, reason := CanonicalForm(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package version
+
+import (
+	"fmt"
+
+	"github.com/aquasecurity/go-version/pkg/part"
+)
+
+// ChangeLevel is the most significant segment that differs between two versions.
+type ChangeLevel int
+
+const (
+	// NoChange means that the versions are equal.
+	NoChange ChangeLevel = iota
+	// LocalChange means that only the local version segments differ, e.g. "1.0+abc" and "1.0+def".
+	LocalChange
+	// DevChange means that the development release segments differ, e.g. "1.0.dev1" and "1.0.dev2".
+	DevChange
+	// PostChange means that the post-release segments differ, e.g. "1.0" and "1.0.post1".
+	PostChange
+	// PreChange means that the pre-release segments differ, e.g. "1.0rc1" and "1.0".
+	PreChange
+	// PatchChange means that the release segments differ from the third number, e.g. "1.0.1" and "1.0.2".
+	PatchChange
+	// MinorChange means that the release segments differ in the second number, e.g. "1.0" and "1.1".
+	MinorChange
+	// MajorChange means that the release segments differ in the first number, e.g. "1.0" and "2.0".
+	MajorChange
+	// EpochChange means that the epochs differ, e.g. "1.0" and "1!1.0".
+	EpochChange
+)
+
+// String returns the name of the change level.
+func (l ChangeLevel) String() string {
+	switch l {
+	case NoChange:
+		return "none"
+	case LocalChange:
+		return "local"
+	case DevChange:
+		return "dev"
+	case PostChange:
+		return "post"
+	case PreChange:
+		return "pre"
+	case PatchChange:
+		return "patch"
+	case MinorChange:
+		return "minor"
+	case MajorChange:
+		return "major"
+	case EpochChange:
+		return "epoch"
+	}
+	return fmt.Sprintf("ChangeLevel(%d)", int(l))
+}
+
+// VersionDiff describes how two versions differ.
+type VersionDiff struct {
+	// Level is the most significant segment that differs.
+	Level ChangeLevel
+	// Direction is -1, 0 or 1 if the other version is smaller, equal or larger, respectively.
+	Direction int
+}
+
+// Diff describes how the other version differs from this version,
+// e.g. "1.2.3" to "1.3.0" is a MinorChange upwards.
+// Release segments are compared after padding with zeros, so "1.0" and "1.0.0" are equal,
+// and only the local segments differ for versions with equal public versions.
+func (v Version) Diff(other Version) VersionDiff {
+	return VersionDiff{
+		Level:     v.changeLevel(other),
+		Direction: other.Compare(v),
+	}
+}
+
+func (v Version) changeLevel(o Version) ChangeLevel {
+	if v.epoch.Compare(o.epoch) != 0 {
+		return EpochChange
+	}
+
+	n := len(v.release)
+	if len(o.release) > n {
+		n = len(o.release)
+	}
+	for i := 0; i < n; i++ {
+		if releaseAt(v.release, i).Compare(releaseAt(o.release, i)) == 0 {
+			continue
+		}
+		switch i {
+		case 0:
+			return MajorChange
+		case 1:
+			return MinorChange
+		}
+		return PatchChange
+	}
+
+	switch {
+	case !letterNumberEqual(v.pre, o.pre):
+		return PreChange
+	case !letterNumberEqual(v.post, o.post):
+		return PostChange
+	case !letterNumberEqual(v.dev, o.dev):
+		return DevChange
+	case v.Compare(o) != 0:
+		return LocalChange
+	}
+	return NoChange
+}
+
+// releaseAt returns the i-th release number, padding with zeros.
+func releaseAt(release []part.BigInt, i int) part.BigInt {
+	if i < len(release) {
+		return release[i]
+	}
+	return intToBigInt(0)
+}
+
+func letterNumberEqual(a, b letterNumber) bool {
+	if a.isNull() || b.isNull() {
+		return a.isNull() == b.isNull()
+	}
+	return a.letter == b.letter && a.number.Compare(b.number) == 0
+}
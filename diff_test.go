@@ -0,0 +1,42 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion_Diff(t *testing.T) {
+	tests := []struct {
+		v1            string
+		v2            string
+		wantLevel     ChangeLevel
+		wantDirection int
+	}{
+		{"1.0", "1.0.0", NoChange, 0},
+		{"1.0+abc", "1.0+abc", NoChange, 0},
+		{"1.0+abc", "1.0+def", LocalChange, 1},
+		{"1.0", "1.0+abc", LocalChange, 1},
+		{"1.0.dev1", "1.0.dev2", DevChange, 1},
+		{"1.0", "1.0.dev1", DevChange, -1},
+		{"1.0", "1.0.post1", PostChange, 1},
+		{"1.0rc1", "1.0", PreChange, 1},
+		{"1.0a1", "1.0b1", PreChange, 1},
+		{"1.0.1", "1.0.2", PatchChange, 1},
+		{"1.0.0.1", "1.0.0", PatchChange, -1},
+		{"1.0.9", "1.1rc1", MinorChange, 1},
+		{"1.9", "2.0", MajorChange, 1},
+		{"2.0", "1!1.0", EpochChange, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			got := MustParse(tt.v1).Diff(MustParse(tt.v2))
+			assert.Equal(t, tt.wantLevel, got.Level, got.Level.String())
+			assert.Equal(t, tt.wantDirection, got.Direction)
+
+			reversed := MustParse(tt.v2).Diff(MustParse(tt.v1))
+			assert.Equal(t, tt.wantLevel, reversed.Level)
+			assert.Equal(t, -tt.wantDirection, reversed.Direction)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package version
+
+import "container/heap"
+
+// MergeSorted merges lists of versions that are each sorted in ascending order into a single
+// sorted list. Equal versions, e.g. "1.0" and "1.0.0", are deduplicated by keeping the first one,
+// with earlier lists taking precedence. The result is unspecified if any list is not sorted.
+func MergeSorted(lists ...[]Version) []Version {
+	h := make(mergeHeap, 0, len(lists))
+	total := 0
+	for i, l := range lists {
+		if len(l) > 0 {
+			h = append(h, mergeCursor{list: i, versions: l})
+		}
+		total += len(l)
+	}
+	heap.Init(&h)
+
+	merged := make([]Version, 0, total)
+	for h.Len() > 0 {
+		c := &h[0]
+		v := c.versions[c.pos]
+		if len(merged) == 0 || !merged[len(merged)-1].Equal(v) {
+			merged = append(merged, v)
+		}
+
+		c.pos++
+		if c.pos == len(c.versions) {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+	return merged
+}
+
+// mergeCursor points to the next version to merge in a list.
+type mergeCursor struct {
+	list     int
+	versions []Version
+	pos      int
+}
+
+// mergeHeap is a min-heap of cursors ordered by their next version.
+type mergeHeap []mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	c := h[i].versions[h[i].pos].Compare(h[j].versions[h[j].pos])
+	if c == 0 {
+		return h[i].list < h[j].list
+	}
+	return c < 0
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeCursor)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,101 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSorted(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]string
+		want  []string
+	}{
+		{
+			name: "happy path",
+			lists: [][]string{
+				{"1.0a1", "1.0", "1.2", "2.0"},
+				{"0.9", "1.0", "1.1.dev1", "3.0"},
+				{"1.0.0", "1.0.post1", "1!0.1"},
+			},
+			want: []string{"0.9", "1.0a1", "1.0", "1.0.post1", "1.1.dev1", "1.2", "2.0", "3.0", "1!0.1"},
+		},
+		{
+			name:  "duplicates in a list",
+			lists: [][]string{{"1.0", "1.0", "1.0.0"}, {"1.0+local"}},
+			want:  []string{"1.0", "1.0+local"},
+		},
+		{
+			name:  "empty lists",
+			lists: [][]string{{}, {"1.0"}, nil},
+			want:  []string{"1.0"},
+		},
+		{
+			name: "no lists",
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lists [][]Version
+			for _, l := range tt.lists {
+				vs, err := ParseList(l)
+				require.NoError(t, err)
+				lists = append(lists, vs)
+			}
+
+			got := MergeSorted(lists...)
+			assert.True(t, sort.IsSorted(SortedVersions(got)))
+
+			gotStrs := []string{}
+			for _, v := range got {
+				gotStrs = append(gotStrs, v.String())
+			}
+			assert.Equal(t, tt.want, gotStrs)
+		})
+	}
+}
+
+func TestMergeSorted_KeepsFirst(t *testing.T) {
+	got := MergeSorted([]Version{MustParse("1.0")}, []Version{MustParse("1.0.0")})
+	require.Len(t, got, 1)
+	assert.Equal(t, "1.0", got[0].Original())
+}
+
+func mergeBenchmarkLists(b *testing.B) [][]Version {
+	b.Helper()
+
+	lists := make([][]Version, 8)
+	for i := range lists {
+		for j := 0; j < 2000; j++ {
+			lists[i] = append(lists[i], MustParse(fmt.Sprintf("%d.%d.%d", j/100, j%100, i)))
+		}
+	}
+	return lists
+}
+
+func BenchmarkMergeSorted(b *testing.B) {
+	lists := mergeBenchmarkLists(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MergeSorted(lists...)
+	}
+}
+
+func BenchmarkMergeSorted_ConcatSort(b *testing.B) {
+	lists := mergeBenchmarkLists(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var all []Version
+		for _, l := range lists {
+			all = append(all, l...)
+		}
+		sort.Sort(SortedVersions(all))
+	}
+}
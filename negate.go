@@ -0,0 +1,85 @@
+package version
+
+// Negate returns specifiers that are satisfied by exactly the versions that don't satisfy these ones.
+// By De Morgan's laws, each specifier is negated and the result is distributed back into OR-groups:
+//   - "==" and "!=" are swapped, including prefix matching, e.g. "==1.4.*" becomes "!=1.4.*"
+//   - ">=X" becomes "<=X, !=X" and "<=X" becomes ">=X, !=X"
+//   - "~=X" becomes "<=X, !=X || !=P.*", where P is the prefix required by "~="
+//
+// ">", "<" and "===" have no exact negation with the other operators due to the special handling
+// of pre-releases, post-releases and local versions, so they are kept as negated specifiers.
+// String displays them with "not", e.g. "not <2.0", but they cannot be parsed back by NewSpecifiers,
+// so MarshalYAML returns an error for them and Operators doesn't report them.
+// The number of OR-groups grows as the product of the number of specifiers in each group.
+func (ss Specifiers) Negate() Specifiers {
+	// The empty group is always satisfied.
+	negated := [][]specifier{{}}
+	for _, orS := range ss.specifiers {
+		var alternatives [][]specifier
+		for _, andS := range orS {
+			alternatives = append(alternatives, andS.negate()...)
+		}
+
+		var next [][]specifier
+		for _, group := range negated {
+			for _, a := range alternatives {
+				g := make([]specifier, 0, len(group)+len(a))
+				g = append(g, group...)
+				g = append(g, a...)
+				next = append(next, g)
+			}
+		}
+		negated = next
+	}
+
+	return Specifiers{
+		specifiers: negated,
+		conf:       ss.conf,
+	}
+}
+
+// negate returns the negation of the specifier as OR-groups.
+func (s specifier) negate() [][]specifier {
+	switch s.op {
+	case "", "=", "==":
+		return [][]specifier{{s.withOperator("!=")}}
+	case "!=":
+		return [][]specifier{{s.withOperator("==")}}
+	case ">=":
+		return [][]specifier{{s.withOperator("<="), s.withOperator("!=")}}
+	case "<=":
+		return [][]specifier{{s.withOperator(">="), s.withOperator("!=")}}
+	case "~=":
+		prefix := MustParse(versionJoin(compatiblePrefix(s.parsed))).String() + ".*"
+		return [][]specifier{
+			{s.withOperator("<="), s.withOperator("!=")},
+			{{
+				version:  prefix,
+				op:       "!=",
+				operator: specifierNotEqual,
+				original: "!=" + prefix,
+			}},
+		}
+	}
+	return [][]specifier{{s.not()}}
+}
+
+// withOperator returns the specifier with the same version and the given operator.
+func (s specifier) withOperator(op string) specifier {
+	s.op = op
+	s.operator = specifierOperators[op]
+	s.original = op + s.version
+	return s
+}
+
+// not returns the specifier satisfied by the versions that don't satisfy this one.
+func (s specifier) not() specifier {
+	operator := s.operator
+	s.operator = func(v Version, s specifier) bool {
+		return !operator(v, s)
+	}
+	s.op = "not " + s.op
+	s.original = "not " + s.original
+	s.negated = true
+	return s
+}
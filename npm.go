@@ -0,0 +1,76 @@
+package version
+
+import (
+	"regexp"
+
+	"golang.org/x/xerrors"
+)
+
+// npmRangeRegexp finds the caret and tilde ranges at the start of a clause, e.g. "^1.2" or "~1.2.3".
+// "~=" is the PEP 440 compatible release operator and is not matched.
+var npmRangeRegexp = regexp.MustCompile(`(^|[\s,|])([\^~])([^\s,|=][^\s,|]*)`)
+
+// NewSpecifiersNpm is like NewSpecifiers but also accepts the npm-style caret and tilde ranges,
+// which are translated into PEP 440 specifiers:
+//   - "^1.2.3" allows changes that don't modify the first non-zero number, i.e. ">=1.2.3,<2.0.0".
+//     "^0.2.3" becomes ">=0.2.3,<0.3.0" and "^0.0.3" becomes ">=0.0.3,<0.0.4".
+//     If all the given numbers are zero, the last one is incremented, e.g. "^0.0" becomes ">=0.0,<0.1.0".
+//   - "~1.2.3" allows patch-level changes, i.e. ">=1.2.3,<1.3.0", and "~1" allows minor-level changes, i.e. ">=1,<2.0.0".
+//
+// The ranges can be combined with PEP 440 specifiers separated by spaces or commas and with "||",
+// e.g. "^1.2 || >=3.0, !=3.1.*". The rest of the input is parsed by NewSpecifiers as-is, and the offset
+// of a *ConstraintParseError is in the given string while its clause index counts the translated clauses.
+// The upper bound is computed from the release segment only, so "^1.2.3-beta.1" becomes ">=1.2.3b1,<2.0.0".
+func NewSpecifiersNpm(s string, opts ...SpecifierOption) (Specifiers, error) {
+	var m offsetMap
+	last := 0
+	for _, loc := range npmRangeRegexp.FindAllStringSubmatchIndex(s, -1) {
+		token, caret := s[loc[4]:loc[1]], s[loc[4]] == '^'
+		spec, err := npmRange(s[loc[6]:loc[7]], caret)
+		if err != nil {
+			kind := "tilde"
+			if caret {
+				kind = "caret"
+			}
+			return Specifiers{}, xerrors.Errorf("invalid %s range (%s): %w", kind, token, err)
+		}
+		m.copy(s[last:loc[4]], last)
+		m.replace(spec, loc[4])
+		last = loc[1]
+	}
+	m.copy(s[last:], last)
+
+	ss, err := NewSpecifiers(m.String(), opts...)
+	if err != nil {
+		return Specifiers{}, m.mapError(err)
+	}
+	return ss, nil
+}
+
+// npmRange translates the version of a caret or tilde range into PEP 440 specifiers.
+func npmRange(s string, caret bool) (string, error) {
+	v, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	i := 0
+	switch {
+	case caret:
+		// The first non-zero number, or the last one if all of them are zero.
+		for i < len(v.release)-1 && v.release[i].IsNull() {
+			i++
+		}
+	case len(v.release) > 1:
+		i = 1
+	}
+
+	upper, err := v.Increment(i)
+	if err != nil {
+		return "", err
+	}
+	if len(upper.release) < 3 {
+		upper = upper.Truncate(3)
+	}
+	return ">=" + v.String() + ",<" + upper.String(), nil
+}
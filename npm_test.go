@@ -0,0 +1,76 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSpecifiersNpm(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "^1.2.3", want: ">=1.2.3,<2.0.0"},
+		{spec: "^1.2", want: ">=1.2,<2.0.0"},
+		{spec: "^1", want: ">=1,<2.0.0"},
+		{spec: "^0.2.3", want: ">=0.2.3,<0.3.0"},
+		{spec: "^0.0.3", want: ">=0.0.3,<0.0.4"},
+		{spec: "^0.0.0", want: ">=0.0.0,<0.0.1"},
+		{spec: "^0.0", want: ">=0.0,<0.1.0"},
+		{spec: "^0", want: ">=0,<1.0.0"},
+		{spec: "^1.2.3-beta.1", want: ">=1.2.3b1,<2.0.0"},
+		{spec: "~1.2.3", want: ">=1.2.3,<1.3.0"},
+		{spec: "~1.2", want: ">=1.2,<1.3.0"},
+		{spec: "~1", want: ">=1,<2.0.0"},
+		{spec: "~0.2.3", want: ">=0.2.3,<0.3.0"},
+		{spec: "^1.2 || >=3.0, !=3.1.*", want: ">=1.2,<2.0.0||>=3.0,!=3.1.*"},
+		{spec: "~=1.4.2", want: "~=1.4.2"},
+		{spec: "^1.2 ~1.2.5", want: ">=1.2,<2.0.0,>=1.2.5,<1.3.0"},
+		{spec: ">= 1.0", want: ">=1.0"},
+		{spec: "^1.2, < 1.5", want: ">=1.2,<2.0.0,<1.5"},
+		{spec: ">= 1.0 || ~ 1.2", wantErr: true},
+		{spec: "^foo", wantErr: true},
+		{spec: "~", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := NewSpecifiersNpm(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewSpecifiersNpm_Check(t *testing.T) {
+	tests := []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.9", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.version, func(t *testing.T) {
+			c, err := NewSpecifiersNpm(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.Check(MustParse(tt.version)))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package version
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/go-version/pkg/part"
+)
+
+// Range represents versions between a lower bound and an upper bound.
+type Range struct {
+	lo          Version
+	loInclusive bool
+	hi          Version
+	hiInclusive bool
+}
+
+// NewRange returns a new Range between lo and hi.
+// Each bound is inclusive (>=, <=) or exclusive (>, <) as specified.
+// An error is returned if a bound is the zero value or has a local segment,
+// which ordered comparisons don't allow, or if lo is greater than hi.
+func NewRange(lo Version, loInclusive bool, hi Version, hiInclusive bool) (Range, error) {
+	for _, v := range []Version{lo, hi} {
+		if v.IsZero() {
+			return Range{}, xerrors.New("range bound must not be the zero version")
+		}
+		if v.Local() != "" {
+			return Range{}, xerrors.Errorf("range bound must not have a local segment: %s", v)
+		}
+	}
+	if lo.GreaterThan(hi) {
+		return Range{}, xerrors.Errorf("lower bound %s is greater than upper bound %s", lo, hi)
+	}
+
+	return Range{
+		lo:          lo,
+		loInclusive: loInclusive,
+		hi:          hi,
+		hiInclusive: hiInclusive,
+	}, nil
+}
+
+// Contains tests if a version is in the range.
+// It gives the same result as checking the version against Specifiers.
+func (r Range) Contains(v Version) bool {
+	return r.Specifiers().Check(v)
+}
+
+// Specifiers converts the range into Specifiers, e.g. ">=1.0, <2.0".
+func (r Range) Specifiers() Specifiers {
+	loOp, hiOp := ">", "<"
+	if r.loInclusive {
+		loOp = ">="
+	}
+	if r.hiInclusive {
+		hiOp = "<="
+	}
+
+	return Specifiers{
+		specifiers: [][]specifier{{
+			newRangeSpecifier(loOp, r.lo),
+			newRangeSpecifier(hiOp, r.hi),
+		}},
+	}
+}
+
+// String returns the string format of the range
+func (r Range) String() string {
+	return r.Specifiers().String()
+}
+
+func newRangeSpecifier(op string, v Version) specifier {
+	return specifier{
+		version:  v.String(),
+		parsed:   v,
+		op:       op,
+		operator: specifierOperators[op],
+		original: fmt.Sprintf("%s%s", op, v),
+	}
+}
+
+// CompatibleBounds returns the lower and upper bounds of a compatible release specifier,
+// e.g. "~=2.2.3" returns "2.2.3" and "2.3". The lower bound is inclusive and the upper bound
+// is exclusive, and pre-releases of the upper bound such as "2.3rc1" are excluded as well.
+// A pre-release is treated as its own segment, so "~=1.4.5a4" returns "1.4.5a4" and "1.4.6".
+func CompatibleBounds(spec string) (Version, Version, error) {
+	ss, err := NewSpecifiers(spec)
+	if err != nil {
+		return Version{}, Version{}, err
+	}
+	s, ok := ss.single()
+	if !ok || s.op != "~=" {
+		return Version{}, Version{}, xerrors.Errorf("not a compatible release specifier: %s", spec)
+	}
+
+	upper, err := compatibleUpperBound(s.parsed)
+	if err != nil {
+		return Version{}, Version{}, xerrors.Errorf("invalid compatible release specifier (%s): %w", spec, err)
+	}
+	return s.parsed, upper, nil
+}
+
+// compatibleUpperBound returns the exclusive upper bound of "~=" with the given version.
+func compatibleUpperBound(v Version) (Version, error) {
+	prefix := compatiblePrefix(v)
+	epoch, err := part.NewBigInt(prefix[0])
+	if err != nil {
+		return Version{}, xerrors.Errorf("invalid epoch: %w", err)
+	}
+
+	var release []part.BigInt
+	for _, r := range prefix[1:] {
+		n, err := part.NewBigInt(r)
+		if err != nil {
+			return Version{}, xerrors.Errorf("invalid release segment: %w", err)
+		}
+		release = append(release, n)
+	}
+	release[len(release)-1] = incrementBigInt(release[len(release)-1])
+
+	return newVersion(epoch, release, letterNumber{}, letterNumber{}, letterNumber{}, ""), nil
+}
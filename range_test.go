@@ -0,0 +1,130 @@
+package version
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRange_Contains(t *testing.T) {
+	tests := []struct {
+		lo          string
+		loInclusive bool
+		hi          string
+		hiInclusive bool
+		version     string
+		want        bool
+	}{
+		{"1.0", true, "2.0", false, "1.0", true},
+		{"1.0", true, "2.0", false, "1.5", true},
+		{"1.0", true, "2.0", false, "2.0", false},
+		{"1.0", true, "2.0", false, "0.9", false},
+		{"1.0", false, "2.0", false, "1.0", false},
+		{"1.0", false, "2.0", true, "2.0", true},
+		{"1.0", false, "2.0", true, "2.0.post1", false},
+		{"1.0", true, "2.0", false, "2.0rc1", false},
+		{"1!1.0", true, "1!2.0", true, "1.5", false},
+	}
+	for _, tt := range tests {
+		r, err := NewRange(MustParse(tt.lo), tt.loInclusive, MustParse(tt.hi), tt.hiInclusive)
+		require.NoError(t, err)
+		t.Run(fmt.Sprintf("%s %s", tt.version, r), func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Contains(MustParse(tt.version)))
+		})
+	}
+}
+
+func TestRange_Specifiers(t *testing.T) {
+	tests := []struct {
+		lo          string
+		loInclusive bool
+		hi          string
+		hiInclusive bool
+		want        string
+	}{
+		{"1.0", true, "2.0", false, ">=1.0,<2.0"},
+		{"1.0a1", false, "2.0", true, ">1.0a1,<=2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			r, err := NewRange(MustParse(tt.lo), tt.loInclusive, MustParse(tt.hi), tt.hiInclusive)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, r.Specifiers().String())
+			assert.Equal(t, tt.want, r.String())
+
+			c, err := NewSpecifiers(tt.want)
+			assert.NoError(t, err)
+			assert.Equal(t, c.String(), r.Specifiers().String())
+		})
+	}
+}
+
+func TestNewRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		lo      Version
+		hi      Version
+		wantErr bool
+	}{
+		{"valid", MustParse("1.0"), MustParse("2.0"), false},
+		{"same bounds", MustParse("1.0"), MustParse("1.0"), false},
+		{"zero lower bound", Version{}, MustParse("2.0"), true},
+		{"zero upper bound", MustParse("1.0"), Version{}, true},
+		{"local lower bound", MustParse("1.0+local"), MustParse("2.0"), true},
+		{"local upper bound", MustParse("1.0"), MustParse("2.0+local"), true},
+		{"lower bound greater than upper bound", MustParse("2.0"), MustParse("1.0"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRange(tt.lo, true, tt.hi, false)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCompatibleBounds(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantLower string
+		wantUpper string
+		wantErr   bool
+	}{
+		// https://peps.python.org/pep-0440/#compatible-release
+		{"~=2.2", "2.2", "3", false},
+		{"~=1.4.5", "1.4.5", "1.5", false},
+		{"~=2.2.post3", "2.2.post3", "3", false},
+		{"~=2.2.0", "2.2.0", "2.3", false},
+		{"~= 2.2.3.dev1", "2.2.3.dev1", "2.3", false},
+		{"~=1!2.9", "1!2.9", "1!3", false},
+		{"~=1.4.5a4", "1.4.5a4", "1.4.6", false},
+		{">=2.2", "", "", true},
+		{"~=2", "", "", true},
+		{"~=2.2.*", "", "", true},
+		{"~=2.2,<2.1", "", "", true},
+		{"~=2.2||~=3.0", "", "", true},
+		{"foo ~=2.2", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			lower, upper, err := CompatibleBounds(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLower, lower.String())
+			assert.Equal(t, tt.wantUpper, upper.String())
+
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+			assert.True(t, c.Check(lower))
+			assert.False(t, c.Check(upper))
+		})
+	}
+}
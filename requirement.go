@@ -0,0 +1,64 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/xerrors"
+)
+
+var (
+	// https://peps.python.org/pep-0508/#names
+	// The name is followed by optional extras, e.g. "requests[security,socks]".
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+)
+
+// ParseRequirement parses a requirement line as found in requirements.txt and returns
+// the package name and the specifiers, e.g. `requests[security]>=2.0,<3.0; python_version<"3.8"`
+// returns "requests" and ">=2.0,<3.0". The extras, the environment markers after ";"
+// and a comment after "#" are ignored, and the specifiers may be enclosed in parentheses
+// as in "requests (>=2.0)". A requirement without specifiers, such as "requests", matches any version as "*".
+// URL requirements such as "pip @ https://..." are not supported.
+func ParseRequirement(line string, opts ...SpecifierOption) (name string, constraints Specifiers, err error) {
+	req := line
+	if i := strings.Index(req, "#"); i >= 0 {
+		req = req[:i]
+	}
+	if i := strings.Index(req, ";"); i >= 0 {
+		req = req[:i]
+	}
+	// start is the byte offset of the trimmed string in the line, so that parse errors point into the line.
+	req, start := trimSpace(req, 0)
+
+	loc := requirementRegex.FindStringSubmatchIndex(req)
+	if loc == nil {
+		return "", Specifiers{}, xerrors.Errorf("malformed requirement: %s", line)
+	}
+	name = req[loc[2]:loc[3]]
+	spec, start := trimSpace(req[loc[4]:loc[5]], start+loc[4])
+	if strings.HasPrefix(spec, "@") {
+		return "", Specifiers{}, xerrors.Errorf("unsupported URL requirement: %s", line)
+	}
+
+	if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+		spec, start = trimSpace(spec[1:len(spec)-1], start+1)
+	}
+	if spec == "" {
+		spec = "*"
+	}
+
+	constraints, err = NewSpecifiers(spec, opts...)
+	if err != nil {
+		err = shiftOffset(err, func(offset int) int { return start + offset })
+		return "", Specifiers{}, xerrors.Errorf("invalid requirement (%s): %w", line, err)
+	}
+	return name, constraints, nil
+}
+
+// trimSpace is like strings.TrimSpace but also returns the offset of the result
+// given the offset of s.
+func trimSpace(s string, offset int) (string, int) {
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	return strings.TrimRightFunc(trimmed, unicode.IsSpace), offset + len(s) - len(trimmed)
+}
@@ -0,0 +1,44 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantName string
+		wantSpec string
+		wantErr  string
+	}{
+		{line: "requests>=2.0,<3.0", wantName: "requests", wantSpec: ">=2.0,<3.0"},
+		{line: `requests>=2.0,<3.0; python_version<"3.8"`, wantName: "requests", wantSpec: ">=2.0,<3.0"},
+		{line: "requests[security]>=2.0", wantName: "requests", wantSpec: ">=2.0"},
+		{line: "requests[security, socks] >= 2.0 ; sys_platform == 'win32'", wantName: "requests", wantSpec: ">=2.0"},
+		{line: "requests (>=2.0, <3.0)", wantName: "requests", wantSpec: ">=2.0,<3.0"},
+		{line: "  zope.interface==5.4.0  # pinned", wantName: "zope.interface", wantSpec: "==5.4.0"},
+		{line: "Django~=4.2", wantName: "Django", wantSpec: "~=4.2"},
+		{line: "typing_extensions", wantName: "typing_extensions", wantSpec: "*"},
+		{line: "pywin32; os_name == 'nt'", wantName: "pywin32", wantSpec: "*"},
+		{line: "pip @ https://github.com/pypa/pip/archive/22.0.zip", wantErr: "unsupported URL requirement"},
+		{line: ">=2.0", wantErr: "malformed requirement"},
+		{line: "", wantErr: "malformed requirement"},
+		{line: "requests>=foo", wantErr: "invalid requirement"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			name, constraints, err := ParseRequirement(tt.line)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantSpec, constraints.String())
+		})
+	}
+}
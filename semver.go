@@ -0,0 +1,182 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/go-version/pkg/part"
+)
+
+var (
+	// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+	// A leading "v" is allowed as it is common in tags.
+	semverRegex = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+		`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?` + // pre-release
+		`(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`) // build metadata
+
+	// A pre-release identifier with an optional number attached, e.g. "alpha" or "rc1".
+	semverLabelRegex = regexp.MustCompile(`^([A-Za-z]+)([0-9]*)$`)
+
+	// The SemVer labels of the normalized pre-release letters used by ToSemver.
+	semverLabels = map[string]string{
+		"a":  "alpha",
+		"b":  "beta",
+		"rc": "rc",
+	}
+)
+
+// FromSemver converts a SemVer 2.0.0 version to a PEP 440 version.
+// The pre-release identifiers are mapped as follows:
+//   - "alpha" and "a" become "a", e.g. "1.2.3-alpha.1" becomes "1.2.3a1"
+//   - "beta" and "b" become "b", e.g. "1.2.3-beta.2" becomes "1.2.3b2"
+//   - "rc", "c", "pre" and "preview" become "rc", e.g. "1.2.3-rc.1" becomes "1.2.3rc1"
+//   - "dev" becomes a development release, e.g. "1.2.3-rc.1.dev.4" becomes "1.2.3rc1.dev4"
+//
+// A label is followed by at most one number, which is either attached to it or in the next
+// dot-separated identifier, so "-alpha.1" and "-alpha1" are the same. A label without a number
+// is numbered 0. The build metadata becomes the local version, e.g. "1.2.3+build.5" becomes "1.2.3+build.5".
+// Any other pre-release identifier, such as "-snapshot" or "-alpha.1.2", has no PEP 440 equivalent
+// and results in an error.
+func FromSemver(s string) (Version, error) {
+	s = strings.TrimSpace(s)
+	m := semverRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, xerrors.Errorf("malformed semver: %s", s)
+	}
+
+	pep440 := m[1] + "." + m[2] + "." + m[3]
+	if m[4] != "" {
+		pre, err := semverPreRelease(m[4])
+		if err != nil {
+			return Version{}, xerrors.Errorf("unsupported semver pre-release (%s): %w", s, err)
+		}
+		pep440 += pre
+	}
+	if m[5] != "" {
+		pep440 += "+" + m[5]
+	}
+
+	v, err := Parse(pep440)
+	if err != nil {
+		return Version{}, xerrors.Errorf("failed to convert semver (%s): %w", s, err)
+	}
+	v.original = s
+	return v, nil
+}
+
+// semverPreRelease converts the SemVer pre-release identifiers to the PEP 440 pre-release
+// and development release segments.
+func semverPreRelease(pre string) (string, error) {
+	var result string
+	var hasPre, hasDev bool
+
+	ids := strings.Split(pre, ".")
+	for i := 0; i < len(ids); {
+		m := semverLabelRegex.FindStringSubmatch(ids[i])
+		if m == nil {
+			return "", xerrors.Errorf("unexpected identifier: %s", ids[i])
+		}
+		label, number := strings.ToLower(m[1]), m[2]
+		i++
+
+		if number == "" && i < len(ids) && isDigits(ids[i]) {
+			number = ids[i]
+			i++
+		}
+		if number == "" {
+			number = "0"
+		}
+
+		switch {
+		case label == "dev":
+			if hasDev {
+				return "", xerrors.New("multiple development releases")
+			}
+			hasDev = true
+			result += ".dev" + number
+		case preReleaseAliases[label] != "":
+			if hasPre || hasDev {
+				return "", xerrors.Errorf("unexpected pre-release label: %s", label)
+			}
+			hasPre = true
+			result += preReleaseAliases[label] + number
+		default:
+			return "", xerrors.Errorf("unknown label: %s", label)
+		}
+	}
+	return result, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ToSemver converts the version to a SemVer 2.0.0 version.
+// The release segment is padded to three segments, e.g. "1.2" becomes "1.2.0",
+// and the pre-release and development release segments are mapped as follows:
+//   - "a" becomes "alpha", e.g. "1.2.3a1" becomes "1.2.3-alpha.1"
+//   - "b" becomes "beta", e.g. "1.2.3b2" becomes "1.2.3-beta.2"
+//   - "rc" stays "rc", e.g. "1.2.3rc1" becomes "1.2.3-rc.1"
+//   - "dev" becomes "dev", e.g. "1.2.3rc1.dev4" becomes "1.2.3-rc.1.dev.4"
+//
+// The local version becomes the build metadata, with "_" replaced by ".".
+// It returns an error for the zero value, an epoch, a post-release or a release segment with more than
+// three non-zero segments, which have no SemVer equivalent.
+//
+// The conversion is lossy: SemVer ignores build metadata in comparisons, and development releases sort
+// differently. A development release without a pre-release, e.g. "1.2.3-dev.1", sorts after "1.2.3-alpha.1",
+// and a development release of a pre-release, e.g. "1.2.3-rc.1.dev.4", sorts after "1.2.3-rc.1",
+// while "1.2.3.dev1" and "1.2.3rc1.dev4" sort before "1.2.3a1" and "1.2.3rc1" in PEP 440.
+// FromSemver converts the result back to an equal version, but not always to the same string:
+// the release segment is padded or trimmed to three segments and "_" in the local version becomes ".",
+// e.g. "1.2+abc_1" comes back as "1.2.0+abc.1".
+func (v Version) ToSemver() (string, error) {
+	if v.IsZero() {
+		return "", xerrors.New("the zero version cannot be converted to semver")
+	}
+	if v.epoch.Compare(part.Zero) == 1 {
+		return "", xerrors.Errorf("an epoch cannot be converted to semver: %s", v)
+	}
+	if !v.post.isNull() {
+		return "", xerrors.Errorf("a post-release cannot be converted to semver: %s", v)
+	}
+
+	release := make([]string, 3)
+	for i := range release {
+		release[i] = "0"
+	}
+	for i, r := range v.release {
+		if i < len(release) {
+			release[i] = r.String()
+		} else if !r.IsNull() {
+			return "", xerrors.Errorf("more than three release segments cannot be converted to semver: %s", v)
+		}
+	}
+	s := strings.Join(release, ".")
+
+	var pre []string
+	if !v.pre.isNull() {
+		pre = append(pre, semverLabels[string(v.pre.letter)], v.pre.number.String())
+	}
+	if !v.dev.isNull() {
+		pre = append(pre, "dev", v.dev.number.String())
+	}
+	if len(pre) > 0 {
+		s += "-" + strings.Join(pre, ".")
+	}
+
+	if v.local != "" {
+		s += "+" + strings.ReplaceAll(v.local, "_", ".")
+	}
+	return s, nil
+}
@@ -0,0 +1,93 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSemver(t *testing.T) {
+	tests := []struct {
+		semver  string
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"v1.2.3", "1.2.3", false},
+		{"1.2.3-alpha.1", "1.2.3a1", false},
+		{"1.2.3-alpha1", "1.2.3a1", false},
+		{"1.2.3-alpha", "1.2.3a0", false},
+		{"1.2.3-beta.2", "1.2.3b2", false},
+		{"1.2.3-RC.1", "1.2.3rc1", false},
+		{"1.2.3-preview.3", "1.2.3rc3", false},
+		{"1.2.3-dev.4", "1.2.3.dev4", false},
+		{"1.2.3-rc.1.dev.4", "1.2.3rc1.dev4", false},
+		{"1.2.3+build.5", "1.2.3+build.5", false},
+		{"1.2.3-alpha.1+build-5", "1.2.3a1+build-5", false},
+		{"1.2", "", true},
+		{"01.2.3", "", true},
+		{"1.2.3.4", "", true},
+		{"1.2.3-snapshot", "", true},
+		{"1.2.3-alpha.1.2", "", true},
+		{"1.2.3-1", "", true},
+		{"1.2.3-alpha.beta", "", true},
+		{"1.2.3-dev.1.rc.1", "", true},
+		{"1.2.3+-build", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.semver, func(t *testing.T) {
+			got, err := FromSemver(tt.semver)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.semver, got.Original())
+		})
+	}
+}
+
+func TestVersion_ToSemver(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"1", "1.0.0", false},
+		{"1.2", "1.2.0", false},
+		{"1.2.3.0", "1.2.3", false},
+		{"1.2.3a1", "1.2.3-alpha.1", false},
+		{"1.2.3beta2", "1.2.3-beta.2", false},
+		{"1.2.3c1", "1.2.3-rc.1", false},
+		{"1.2.3rc", "1.2.3-rc.0", false},
+		{"1.2.3.dev4", "1.2.3-dev.4", false},
+		{"1.2.3rc1.dev4", "1.2.3-rc.1.dev.4", false},
+		{"1.2.3+build.5", "1.2.3+build.5", false},
+		{"1.2.3a1+ubuntu_1", "1.2.3-alpha.1+ubuntu.1", false},
+		{"1!1.2.3", "", true},
+		{"1.2.3.post1", "", true},
+		{"1.2.3.4", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v := MustParse(tt.version)
+			got, err := v.ToSemver()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// Round trip
+			back, err := FromSemver(got)
+			require.NoError(t, err)
+			assert.True(t, back.Equal(v), back.String())
+		})
+	}
+	_, err := Version{}.ToSemver()
+	assert.Error(t, err)
+}
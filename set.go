@@ -0,0 +1,99 @@
+package version
+
+import "sort"
+
+// VersionSet is a set of versions, e.g. an allowlist of approved versions.
+// Equal versions are the same member, e.g. "1.0" and "1.0.0", as they are keyed by CanonicalKey.
+// The zero value is an empty set ready to use.
+type VersionSet struct {
+	versions map[string]Version
+}
+
+// NewVersionSet returns a new set containing the given versions.
+func NewVersionSet(vs ...Version) VersionSet {
+	s := VersionSet{versions: make(map[string]Version, len(vs))}
+	for _, v := range vs {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add adds the version to the set. If an equal version is already in the set, the set is unchanged.
+func (s *VersionSet) Add(v Version) {
+	if s.versions == nil {
+		s.versions = map[string]Version{}
+	}
+	key := v.CanonicalKey()
+	if _, ok := s.versions[key]; !ok {
+		s.versions[key] = v
+	}
+}
+
+// Contains tests if the set contains a version equal to the given one.
+func (s VersionSet) Contains(v Version) bool {
+	_, ok := s.versions[v.CanonicalKey()]
+	return ok
+}
+
+// ContainsString is like Contains but parses the version first.
+// It returns false if the version cannot be parsed.
+func (s VersionSet) ContainsString(v string) bool {
+	ver, err := Parse(v)
+	if err != nil {
+		return false
+	}
+	return s.Contains(ver)
+}
+
+// Len returns the number of versions in the set.
+func (s VersionSet) Len() int {
+	return len(s.versions)
+}
+
+// Versions returns the versions in the set sorted in ascending order.
+func (s VersionSet) Versions() []Version {
+	vs := make([]Version, 0, len(s.versions))
+	for _, v := range s.versions {
+		vs = append(vs, v)
+	}
+	sort.Sort(SortedVersions(vs))
+	return vs
+}
+
+// Union returns a new set containing the versions in either set.
+// For equal versions, the one in this set is kept.
+func (s VersionSet) Union(other VersionSet) VersionSet {
+	u := NewVersionSet()
+	for k, v := range s.versions {
+		u.versions[k] = v
+	}
+	for k, v := range other.versions {
+		if _, ok := u.versions[k]; !ok {
+			u.versions[k] = v
+		}
+	}
+	return u
+}
+
+// Intersect returns a new set containing the versions in both sets.
+// For equal versions, the one in this set is kept.
+func (s VersionSet) Intersect(other VersionSet) VersionSet {
+	i := NewVersionSet()
+	for k, v := range s.versions {
+		if _, ok := other.versions[k]; ok {
+			i.versions[k] = v
+		}
+	}
+	return i
+}
+
+// Difference returns a new set containing the versions in this set but not in the other set.
+func (s VersionSet) Difference(other VersionSet) VersionSet {
+	d := NewVersionSet()
+	for k, v := range s.versions {
+		if _, ok := other.versions[k]; !ok {
+			d.versions[k] = v
+		}
+	}
+	return d
+}
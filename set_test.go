@@ -0,0 +1,67 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVersionSet(t *testing.T, versions ...string) VersionSet {
+	vs, err := ParseList(versions)
+	require.NoError(t, err)
+	return NewVersionSet(vs...)
+}
+
+func setStrings(s VersionSet) []string {
+	var ss []string
+	for _, v := range s.Versions() {
+		ss = append(ss, v.String())
+	}
+	return ss
+}
+
+func TestVersionSet(t *testing.T) {
+	var s VersionSet
+	assert.Equal(t, 0, s.Len())
+	assert.False(t, s.ContainsString("1.0"))
+
+	s.Add(MustParse("1.0"))
+	s.Add(MustParse("1.0.0"))
+	s.Add(MustParse("2.0a1"))
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, []string{"1.0", "2.0a1"}, setStrings(s))
+
+	assert.True(t, s.Contains(MustParse("1.0.0.0")))
+	assert.True(t, s.ContainsString("v2.0alpha1"))
+	assert.False(t, s.ContainsString("1.0+local"))
+	assert.False(t, s.ContainsString("2.0"))
+	assert.False(t, s.ContainsString("not a version"))
+}
+
+func TestVersionSet_Algebra(t *testing.T) {
+	a := newTestVersionSet(t, "1.0", "1.1", "2.0rc1", "3.0+local")
+	b := newTestVersionSet(t, "1.1.0", "2.0", "3.0+local", "4.0")
+
+	tests := []struct {
+		name string
+		got  VersionSet
+		want []string
+	}{
+		{"union", a.Union(b), []string{"1.0", "1.1", "2.0rc1", "2.0", "3.0+local", "4.0"}},
+		{"intersect", a.Intersect(b), []string{"1.1", "3.0+local"}},
+		{"difference", a.Difference(b), []string{"1.0", "2.0rc1"}},
+		{"reverse difference", b.Difference(a), []string{"2.0", "4.0"}},
+		{"empty intersect", a.Intersect(VersionSet{}), nil},
+		{"empty union", VersionSet{}.Union(b), []string{"1.1.0", "2.0", "3.0+local", "4.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, setStrings(tt.got))
+		})
+	}
+
+	// The operands are not modified.
+	assert.Equal(t, 4, a.Len())
+	assert.Equal(t, 4, b.Len())
+}
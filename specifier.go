@@ -6,8 +6,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -46,7 +48,7 @@ func init() {
 	prefixRegexp = regexp.MustCompile(`^([0-9]+)((?:a|b|c|rc)[0-9]+)$`)
 }
 
-type operatorFunc func(v Version, c string) bool
+type operatorFunc func(v Version, s specifier) bool
 
 type Specifiers struct {
 	specifiers [][]specifier
@@ -54,9 +56,33 @@ type Specifiers struct {
 }
 
 type specifier struct {
-	version  string
+	version string
+	// parsed is the pre-parsed version, so that it is not parsed on every check.
+	// It is not set for wildcards and arbitrary equality.
+	parsed   Version
+	op       string
 	operator operatorFunc
 	original string
+	// negated is set for the specifiers negated by Negate that have no PEP 440 operator.
+	negated bool
+}
+
+// ValidConstraint tests if the given string has a valid specifier syntax without parsing it into Specifiers.
+// Only the syntax is checked, so rules such as "~=" requiring at least two release segments
+// are left to NewSpecifiers, e.g. "~=1" is valid syntax but cannot be parsed.
+func ValidConstraint(v string) bool {
+	for _, vv := range strings.Split(v, "||") {
+		switch strings.TrimSpace(vv) {
+		case "*":
+			continue
+		case "":
+			return false
+		}
+		if !isASCII(vv) || !validConstraintRegexp.MatchString(vv) {
+			return false
+		}
+	}
+	return true
 }
 
 // NewSpecifiersWithSanitizer parses a given specifier and returns a new instance of Specifiers
@@ -66,10 +92,114 @@ func NewSpecifiersWithSanitizer(v string, sanitizer func(string) string, opts ..
 }
 
 // NewSpecifiers parses a given specifier and returns a new instance of Specifiers
+// "*" matches any version, while an empty string is an error.
+// A version without an operator is treated as "==", e.g. "1.2.3" is the same as "==1.2.3",
+// but a clause that is not a valid version is still an error.
+// A malformed clause is reported as *ConstraintParseError with its position.
 func NewSpecifiers(v string, opts ...SpecifierOption) (Specifiers, error) {
 	return newSpecifiers(v, func(s string) string { return s }, opts...)
 }
 
+// NewSpecifiersWithSeparators is like NewSpecifiers but uses the given separators
+// instead of "||" and ",", e.g. ">=1.0; <2.0 or ==3.0" with " or " and ";".
+// The separators must not appear inside a specifier. In particular, a word such as "or"
+// should be surrounded by spaces since "r" is a valid post-release spelling.
+func NewSpecifiersWithSeparators(v, or, and string, opts ...SpecifierOption) (Specifiers, error) {
+	if or == "" || and == "" || or == and {
+		return Specifiers{}, xerrors.Errorf("invalid separators: %q and %q", or, and)
+	}
+
+	var m offsetMap
+	pos := 0
+	for i, group := range strings.Split(v, or) {
+		if i > 0 {
+			m.replace("||", pos)
+			pos += len(or)
+		}
+		for j, clause := range strings.Split(group, and) {
+			if j > 0 {
+				m.replace(",", pos)
+				pos += len(and)
+			}
+			m.copy(clause, pos)
+			pos += len(clause)
+		}
+	}
+
+	ss, err := NewSpecifiers(m.String(), opts...)
+	if err != nil {
+		return Specifiers{}, m.mapError(err)
+	}
+	return ss, nil
+}
+
+// NewSpecifiersAll parses each of the given specifiers independently so that all the errors
+// can be reported at once. Both results have the same length as the input, and the error
+// at index i is nil if the i-th specifier could be parsed.
+func NewSpecifiersAll(inputs []string, opts ...SpecifierOption) ([]Specifiers, []error) {
+	specs := make([]Specifiers, len(inputs))
+	errs := make([]error, len(inputs))
+	for i, input := range inputs {
+		specs[i], errs[i] = NewSpecifiers(input, opts...)
+	}
+	return specs, errs
+}
+
+// NewSpecifier returns a new instance of Specifiers with a single clause built from
+// the given operator and version, e.g. NewSpecifier(">=", "1.0").
+func NewSpecifier(operator, version string, opts ...SpecifierOption) (Specifiers, error) {
+	if _, ok := specifierOperators[operator]; !ok {
+		return Specifiers{}, xerrors.Errorf("unknown operator: %q", operator)
+	}
+
+	version = strings.TrimSpace(version)
+	if operator == "===" {
+		if _, err := Parse(version); err != nil {
+			return Specifiers{}, xerrors.Errorf("version parse error (%s): %w", version, err)
+		}
+	}
+
+	s, err := buildSpecifier(operator, version, operator+version)
+	if err != nil {
+		return Specifiers{}, err
+	}
+
+	c := new(conf)
+	for _, o := range opts {
+		o.apply(c)
+	}
+
+	return Specifiers{
+		specifiers: [][]specifier{{s}},
+		conf:       *c,
+	}, nil
+}
+
+// ExcludeVersions returns specifiers with a single OR-group of "!=" clauses that is satisfied by any version
+// except the given ones, e.g. "1.0" and "1.2rc1" give "!=1.0,!=1.2rc1". An empty list gives "*".
+// As with "!=", a version without a local segment excludes its local versions as well, e.g. "1.0+local".
+// The versions are validated for "!=" as in NewSpecifiers.
+func ExcludeVersions(vs []Version, opts ...SpecifierOption) (Specifiers, error) {
+	specs := make([]specifier, 0, len(vs))
+	for _, v := range vs {
+		s, err := buildSpecifier("!=", v.String(), "!="+v.String())
+		if err != nil {
+			return Specifiers{}, xerrors.Errorf("invalid version to exclude (%s): %w", v, err)
+		}
+		specs = append(specs, s)
+	}
+
+	c := new(conf)
+	for _, o := range opts {
+		o.apply(c)
+	}
+
+	return Specifiers{
+		specifiers: [][]specifier{specs},
+		conf:       *c,
+	}, nil
+}
+
 // NewSpecifiers parses a given specifier and returns a new instance of Specifiers
 func newSpecifiers(v string, santizer func(string) string, opts ...SpecifierOption) (Specifiers, error) {
 	c := new(conf)
@@ -80,30 +210,53 @@ func newSpecifiers(v string, santizer func(string) string, opts ...SpecifierOpti
 	}
 
 	var sss [][]specifier
+	// The index of the first clause and the byte offset of the current OR-group for errors
+	clause, offset := 0, 0
 	for _, vv := range strings.Split(v, "||") {
+		// "*" matches any version, including pre-releases and development releases,
+		// so it is represented as a group without specifiers.
 		if strings.TrimSpace(vv) == "*" {
-			vv = ">=0.0.0"
+			sss = append(sss, []specifier{})
+			clause++
+			offset += len(vv) + len("||")
+			continue
 		}
 
 		// Validate the segment
-		if !validConstraintRegexp.MatchString(vv) {
-			return Specifiers{}, xerrors.Errorf("improper constraint: %s", vv)
+		if !isASCII(vv) || !validConstraintRegexp.MatchString(vv) {
+			i, off := invalidClause(vv)
+			return Specifiers{}, &ConstraintParseError{
+				Clause: clause + i,
+				Offset: offset + off,
+				Err:    xerrors.Errorf("improper constraint: %s", vv),
+			}
 		}
 
-		ss := specifierRegexp.FindAllString(vv, -1)
-		if ss == nil {
-			ss = append(ss, strings.TrimSpace(vv))
+		locs := specifierRegexp.FindAllStringIndex(vv, -1)
+		if locs == nil {
+			start := len(vv) - len(strings.TrimLeftFunc(vv, unicode.IsSpace))
+			end := len(strings.TrimRightFunc(vv, unicode.IsSpace))
+			if end < start {
+				end = start
+			}
+			locs = append(locs, []int{start, end})
 		}
 
 		var specs []specifier
-		for _, single := range ss {
-			s, err := newSpecifier(single, santizer)
+		for i, loc := range locs {
+			s, err := newSpecifier(vv[loc[0]:loc[1]], santizer)
 			if err != nil {
-				return Specifiers{}, err
+				return Specifiers{}, &ConstraintParseError{
+					Clause: clause + i,
+					Offset: offset + loc[0],
+					Err:    err,
+				}
 			}
 			specs = append(specs, s)
 		}
 		sss = append(sss, specs)
+		clause += len(specs)
+		offset += len(vv) + len("||")
 	}
 
 	return Specifiers{
@@ -123,16 +276,142 @@ func newSpecifier(s string, sanitizer func(s string) string) (specifier, error)
 	version := m[specifierRegexp.SubexpIndex("version")]
 	version = sanitizer(version)
 
+	return buildSpecifier(operator, version, s)
+}
+
+// ConstraintParseError is returned by NewSpecifiers when a clause cannot be parsed,
+// e.g. for ">=1.0,foo,<2.0", Clause is 1 and Offset is 6.
+type ConstraintParseError struct {
+	// Clause is the zero-based index of the clause counted across all the OR-groups,
+	// where "*" counts as a clause.
+	Clause int
+	// Offset is the byte offset of the clause in the string given to NewSpecifiers.
+	// The wrappers that rewrite their input, such as NewSpecifiersWithSeparators, NewSpecifiersNpm
+	// and ParseRequirement, map it back to the string given to them.
+	Offset int
+	Err    error
+}
+
+func (e *ConstraintParseError) Error() string {
+	return fmt.Sprintf("%s (clause %d at offset %d)", e.Err, e.Clause, e.Offset)
+}
+
+func (e *ConstraintParseError) Unwrap() error {
+	return e.Err
+}
+
+// offsetMap builds a string rewritten from an input and maps the byte offsets in it back to the input.
+type offsetMap struct {
+	b      strings.Builder
+	chunks []offsetChunk
+}
+
+// offsetChunk is a part of the rewritten string starting at offset in it and at input in the input.
+// A verbatim chunk is copied from the input, so the offsets within it map one-to-one.
+type offsetChunk struct {
+	offset, input int
+	verbatim      bool
+}
+
+// copy appends s, which is found at the given offset of the input.
+func (m *offsetMap) copy(s string, input int) {
+	m.chunks = append(m.chunks, offsetChunk{offset: m.b.Len(), input: input, verbatim: true})
+	m.b.WriteString(s)
+}
+
+// replace appends s, which replaces the text at the given offset of the input.
+func (m *offsetMap) replace(s string, input int) {
+	m.chunks = append(m.chunks, offsetChunk{offset: m.b.Len(), input: input})
+	m.b.WriteString(s)
+}
+
+func (m *offsetMap) String() string {
+	return m.b.String()
+}
+
+// inputOffset returns the offset in the input corresponding to the offset in the rewritten string.
+func (m *offsetMap) inputOffset(offset int) int {
+	input := offset
+	for _, c := range m.chunks {
+		if c.offset > offset {
+			break
+		}
+		input = c.input
+		if c.verbatim {
+			input += offset - c.offset
+		}
+	}
+	return input
+}
+
+// mapError maps the offset of a *ConstraintParseError returned by NewSpecifiers back to the input.
+func (m *offsetMap) mapError(err error) error {
+	return shiftOffset(err, m.inputOffset)
+}
+
+// shiftOffset returns a copy of a *ConstraintParseError with the offset converted by f.
+// Any other error is returned as is.
+func shiftOffset(err error, f func(int) int) error {
+	pe, ok := err.(*ConstraintParseError)
+	if !ok {
+		return err
+	}
+	mapped := *pe
+	mapped.Offset = f(pe.Offset)
+	return &mapped
+}
+
+// invalidClause returns the index and the byte offset of the first malformed clause in an OR-group.
+// The valid clauses before it are skipped with specifierRegexp, e.g. ">=1.0 <2.0, foo" gives 2 and 12.
+func invalidClause(group string) (int, int) {
+	index, pos := 0, 0
+	for _, piece := range strings.Split(group, ",") {
+		if strings.TrimSpace(piece) != "" && isASCII(piece) && validConstraintRegexp.MatchString(piece) {
+			index += len(specifierRegexp.FindAllStringIndex(piece, -1))
+			pos += len(piece) + len(",")
+			continue
+		}
+
+		// Skip the valid clauses at the start of the piece.
+		start, end := 0, 0
+		for _, loc := range specifierRegexp.FindAllStringIndex(piece, -1) {
+			if strings.TrimSpace(piece[end:loc[0]]) != "" {
+				break
+			}
+			start, end = loc[0], loc[1]
+			index++
+		}
+		rest := piece[end:]
+		if end > 0 && (strings.TrimSpace(rest) == "" || !unicode.IsSpace(rune(rest[0]))) {
+			// The last clause is invalid itself, e.g. with a non-ASCII letter,
+			// or continues with invalid characters, e.g. ">=1.0foo".
+			return index - 1, pos + start
+		}
+		return index, pos + len(piece) - len(strings.TrimLeftFunc(rest, unicode.IsSpace))
+	}
+	return index, pos
+}
+
+func buildSpecifier(operator, version, original string) (specifier, error) {
+	var parsed Version
 	if operator != "===" {
 		if err := validate(operator, version); err != nil {
 			return specifier{}, err
 		}
+		if strings.HasSuffix(version, ".*") {
+			// Normalize the prefix since it is compared component by component, e.g. "1.04.*" is "1.4.*".
+			version = MustParse(strings.TrimSuffix(version, ".*")).String() + ".*"
+		} else {
+			parsed = MustParse(version)
+		}
 	}
 
 	return specifier{
 		version:  version,
+		parsed:   parsed,
+		op:       operator,
 		operator: specifierOperators[operator],
-		original: s,
+		original: original,
 	}, nil
 }
 
@@ -173,31 +452,587 @@ func validate(operator, version string) error {
 
 // Check tests if a version satisfies all the specifiers.
 func (ss Specifiers) Check(v Version) bool {
+	_, ok := ss.MatchingBranch(v)
+	return ok
+}
+
+// CheckAll tests each version against the specifiers and returns the results in the same order.
+func (ss Specifiers) CheckAll(vs []Version) []bool {
+	results := make([]bool, len(vs))
+	for i, v := range vs {
+		results[i] = ss.Check(v)
+	}
+	return results
+}
+
+// MatchingBranch returns the zero-based index of the first OR-group satisfied by the version,
+// e.g. 1 for "2.5" with ">=1.0, <2.0 || >=2.0, <3.0". It returns -1 and false if the version doesn't satisfy
+// the specifiers, so that ok is the same as the result of Check.
+func (ss Specifiers) MatchingBranch(v Version) (index int, ok bool) {
 	if ss.conf.includePreRelease {
 		v.preReleaseIncluded = true
 	}
 
-	for _, s := range ss.specifiers {
+	for i, s := range ss.specifiers {
 		if andCheck(v, s) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// CheckFunc returns Check as a predicate that can be passed to helpers such as slices.IndexFunc.
+// The specifiers are parsed only once, so calling the predicate costs the same as Check.
+func (ss Specifiers) CheckFunc() func(Version) bool {
+	return ss.Check
+}
+
+// SatisfiesAny tests if the version satisfies any of the specifiers, e.g. constraints from independent sources.
+// It returns false if no specifiers are given.
+func SatisfiesAny(v Version, css ...Specifiers) bool {
+	for _, ss := range css {
+		if ss.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// SatisfiesAll tests if the version satisfies all the specifiers.
+// It returns true if no specifiers are given.
+func SatisfiesAll(v Version, css ...Specifiers) bool {
+	for _, ss := range css {
+		if !ss.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyMatch tests if any of the versions satisfies the specifiers.
+// It stops at the first version that satisfies them.
+func (ss Specifiers) AnyMatch(vs []Version) bool {
+	for _, v := range vs {
+		if ss.Check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prefer returns the best version satisfying the specifiers among the given versions.
+// If the specifiers are an exact pin (see HasExactPin), the pinned version is preferred
+// over other matching versions such as local versions, e.g. "1.0" over "1.0+local" for "==1.0".
+// Otherwise, the greatest matching version is returned. It returns false if no version matches.
+func (ss Specifiers) Prefer(vs []Version) (Version, bool) {
+	if pin, ok := ss.HasExactPin(); ok {
+		for _, v := range vs {
+			if v.Equal(pin) {
+				return v, true
+			}
+		}
+	}
+
+	var best Version
+	found := false
+	for _, v := range vs {
+		if ss.Check(v) && (!found || v.GreaterThan(best)) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
+
+// SelectBest returns the version pip would install among the given versions:
+// the greatest matching version that is not a pre-release, or the greatest matching pre-release
+// if only pre-releases match, e.g. ">=1.0" selects "1.1" from "1.1" and "1.2rc1", but "1.2rc1" from "0.9" and "1.2rc1".
+// If pre-releases are allowed by WithPreRelease or AllowPreReleases, or a specifier names a pre-release
+// as pip allows, the greatest matching version is returned, e.g. ">=1.0rc1" selects "2.0a1" from "1.0" and "2.0a1".
+// It returns false if no version matches.
+func (ss Specifiers) SelectBest(vs []Version) (Version, bool) {
+	allowPre := ss.allowsPreReleases()
+
+	var best, bestPre Version
+	found, foundPre := false, false
+	for _, v := range vs {
+		if !ss.Check(v) {
+			continue
+		}
+		if !allowPre && v.IsPreRelease() {
+			if !foundPre || v.GreaterThan(bestPre) {
+				bestPre, foundPre = v, true
+			}
+			continue
+		}
+		if !found || v.GreaterThan(best) {
+			best, found = v, true
+		}
+	}
+
+	if found {
+		return best, true
+	}
+	return bestPre, foundPre
+}
+
+// allowsPreReleases tests if pre-releases are allowed by WithPreRelease or AllowPreReleases,
+// or by a specifier naming a pre-release like in pypa/packaging, e.g. ">=1.0rc1" or "==2.0b1.*".
+// Only "==", ">=", "<=", "~=" and "===" count, so "<2.0rc1" doesn't allow pre-releases.
+func (ss Specifiers) allowsPreReleases() bool {
+	if ss.conf.includePreRelease {
+		return true
+	}
+	for _, orS := range ss.specifiers {
+		for _, s := range orS {
+			switch s.op {
+			case "", "=", "==", ">=", "<=", "~=", "===":
+			default:
+				continue
+			}
+			v, err := Parse(strings.TrimSuffix(s.version, ".*"))
+			if err == nil && v.IsPreRelease() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowPreReleases returns a copy of the specifiers with pre-releases allowed or not.
+// It is equivalent to passing WithPreRelease when creating the specifiers.
+func (ss Specifiers) AllowPreReleases(allow bool) Specifiers {
+	ss.conf.includePreRelease = allow
+	return ss
+}
+
+// FilterStable returns the versions satisfying the specifiers, preserving their order.
+// Pre-releases are excluded unless they are allowed by WithPreRelease or AllowPreReleases,
+// like pip does without --pre. As in pip, a specifier naming a pre-release allows them as well,
+// e.g. ">=1.0rc1" matches "1.0rc2" and "1.1.dev1".
+func (ss Specifiers) FilterStable(vs []Version) []Version {
+	allowPre := ss.allowsPreReleases()
+
+	var filtered []Version
+	for _, v := range vs {
+		if !allowPre && v.IsPreRelease() {
+			continue
+		}
+		if ss.Check(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// Equivalent tests if both specifiers give the same result for every version in the sample.
+// It is sample-based, so it doesn't prove that they accept the same set of versions in general.
+func (ss Specifiers) Equivalent(other Specifiers, sample []Version) bool {
+	for _, v := range sample {
+		if ss.Check(v) != other.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasExactPin returns the pinned version if the specifiers consist of a single "==" clause
+// without a wildcard, e.g. "==1.2.3". The operator-less and "=" forms are treated as "==".
+// Arbitrary equality ("===") is reported by HasArbitraryPin instead.
+func (ss Specifiers) HasExactPin() (Version, bool) {
+	s, ok := ss.single()
+	if !ok {
+		return Version{}, false
+	}
+
+	switch s.op {
+	case "", "=", "==":
+		if strings.HasSuffix(s.version, ".*") {
+			return Version{}, false
+		}
+		return s.parsed, true
+	}
+	return Version{}, false
+}
+
+// HasArbitraryPin returns the pinned string if the specifiers consist of a single "===" clause.
+// The string is returned as-is since arbitrary equality doesn't require a valid version.
+func (ss Specifiers) HasArbitraryPin() (string, bool) {
+	s, ok := ss.single()
+	if !ok || s.op != "===" {
+		return "", false
+	}
+	return s.version, true
+}
+
+// AnchorVersion returns the version of the specifiers if they consist of a single clause,
+// e.g. "~=2.2", ">=2.2" and "==2.2" all return "2.2". The prefix of a wildcard is returned,
+// e.g. "==2.2.*" returns "2.2", and "===" returns the version only if it can be parsed.
+// It returns false for multiple clauses or OR-groups and for the negated specifiers returned by Negate.
+func (ss Specifiers) AnchorVersion() (Version, bool) {
+	s, ok := ss.single()
+	if !ok {
+		return Version{}, false
+	}
+
+	switch {
+	case s.op == "===":
+		v, err := Parse(s.version)
+		if err != nil {
+			return Version{}, false
+		}
+		return v, true
+	case strings.HasSuffix(s.version, ".*"):
+		return MustParse(strings.TrimSuffix(s.version, ".*")), true
+	}
+	return s.parsed, true
+}
+
+// Operators returns the distinct operators used in the specifiers in order of appearance.
+// The operator-less form is reported as "==". The negated specifiers returned by Negate,
+// such as "not <2.0", are skipped since they have no PEP 440 operator.
+func (ss Specifiers) Operators() []string {
+	var ops []string
+	seen := map[string]bool{}
+	for _, orS := range ss.specifiers {
+		for _, andS := range orS {
+			op := andS.operatorString()
+			if andS.negated || seen[op] {
+				continue
+			}
+			seen[op] = true
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// Originals returns the specifiers as written in the input, grouped by OR-group and trimmed of surrounding whitespace,
+// e.g. ">= 1.0, <2.0 || ==3.0" returns [[">= 1.0" "<2.0"] ["==3.0"]].
+// Unlike String, the operators and versions are not normalized. The group for "*" is empty.
+func (ss Specifiers) Originals() [][]string {
+	originals := make([][]string, 0, len(ss.specifiers))
+	for _, orS := range ss.specifiers {
+		group := make([]string, 0, len(orS))
+		for _, andS := range orS {
+			group = append(group, strings.TrimSpace(andS.original))
+		}
+		originals = append(originals, group)
+	}
+	return originals
+}
+
+// IsSatisfiable reports whether some version may satisfy the specifiers.
+// It returns false only if every OR-group contains an obvious contradiction:
+//   - a lower bound greater than an upper bound, e.g. ">=2.0, <1.0"
+//   - conflicting exact pins, e.g. "==1.0, ==2.0"
+//   - an exact pin excluded by another clause, e.g. "==1.0, !=1.0" or "==1.0, >1.0"
+//
+// Other contradictions, such as those involving wildcards, "~=" or the special handling
+// of pre-releases in "<" and ">", are not detected, so true doesn't guarantee that
+// any version satisfies the specifiers.
+func (ss Specifiers) IsSatisfiable() bool {
+	for _, s := range ss.specifiers {
+		if ss.isSatisfiable(s) {
 			return true
 		}
 	}
+	return false
+}
+
+func (ss Specifiers) isSatisfiable(specifiers []specifier) bool {
+	var lo, hi *Version
+	var loInclusive, hiInclusive bool
+	var pins []Version
+	for _, s := range specifiers {
+		if strings.HasSuffix(s.version, ".*") {
+			continue
+		}
+
+		switch s.op {
+		case "", "=", "==":
+			v := s.parsed
+			v.preReleaseIncluded = ss.conf.includePreRelease
+			pins = append(pins, v)
+		case ">", ">=":
+			v := s.parsed
+			if lo == nil || v.GreaterThan(*lo) || (v.Equal(*lo) && s.op == ">") {
+				lo, loInclusive = &v, s.op == ">="
+			}
+		case "<", "<=":
+			v := s.parsed
+			if hi == nil || v.LessThan(*hi) || (v.Equal(*hi) && s.op == "<") {
+				hi, hiInclusive = &v, s.op == "<="
+			}
+		}
+	}
+
+	// Only versions equal to a pin can satisfy the group, so it is enough to check the pins.
+	if len(pins) > 0 {
+		for _, pin := range pins {
+			if andCheck(pin, specifiers) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lo != nil && hi != nil {
+		c := lo.Compare(*hi)
+		if c > 0 || (c == 0 && !(loInclusive && hiInclusive)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the lower and upper bounds of the specifiers if they consist of a single
+// OR-group describing an interval, e.g. ">=1.0, <2.0" or "~=1.4.5", where "~=1.4.5" is
+// ">=1.4.5, <1.5". A nil bound means that the interval is unbounded on that side.
+// ok is false if there are multiple OR-groups, a specifier that doesn't describe an interval
+// such as "!=", "===" or a wildcard, or an empty interval.
+// The special handling of pre-releases and post-releases in "<" and ">" is not reflected.
+func (ss Specifiers) Bounds() (lower *Version, lowerInclusive bool, upper *Version, upperInclusive bool, ok bool) {
+	if len(ss.specifiers) != 1 {
+		return nil, false, nil, false, false
+	}
+
+	setLower := func(v Version, inclusive bool) {
+		if lower == nil || v.GreaterThan(*lower) || (v.Equal(*lower) && !inclusive) {
+			lower, lowerInclusive = &v, inclusive
+		}
+	}
+	setUpper := func(v Version, inclusive bool) {
+		if upper == nil || v.LessThan(*upper) || (v.Equal(*upper) && !inclusive) {
+			upper, upperInclusive = &v, inclusive
+		}
+	}
+
+	for _, s := range ss.specifiers[0] {
+		if strings.HasSuffix(s.version, ".*") {
+			return nil, false, nil, false, false
+		}
+
+		switch s.op {
+		case "", "=", "==":
+			setLower(s.parsed, true)
+			setUpper(s.parsed, true)
+		case ">=", ">":
+			setLower(s.parsed, s.op == ">=")
+		case "<=", "<":
+			setUpper(s.parsed, s.op == "<=")
+		case "~=":
+			u, err := compatibleUpperBound(s.parsed)
+			if err != nil {
+				return nil, false, nil, false, false
+			}
+			setLower(s.parsed, true)
+			setUpper(u, false)
+		default:
+			return nil, false, nil, false, false
+		}
+	}
+
+	if lower != nil && upper != nil {
+		c := lower.Compare(*upper)
+		if c > 0 || (c == 0 && !(lowerInclusive && upperInclusive)) {
+			return nil, false, nil, false, false
+		}
+	}
+	return lower, lowerInclusive, upper, upperInclusive, true
+}
+
+// And returns new specifiers with the given clause added to every OR-group,
+// e.g. ">=1.0 || ==0.9" and "!=1.3" give ">=1.0,!=1.3||==0.9,!=1.3".
+// The clause must be a single specifier such as "<2.0"; it is validated as in NewSpecifiers.
+func (ss Specifiers) And(clause string) (Specifiers, error) {
+	c, err := NewSpecifiers(clause)
+	if err != nil {
+		return Specifiers{}, xerrors.Errorf("invalid clause (%s): %w", clause, err)
+	}
+	s, ok := c.single()
+	if !ok {
+		return Specifiers{}, xerrors.Errorf("not a single clause: %s", clause)
+	}
+
+	sss := make([][]specifier, 0, len(ss.specifiers))
+	for _, orS := range ss.specifiers {
+		specs := make([]specifier, 0, len(orS)+1)
+		specs = append(specs, orS...)
+		sss = append(sss, append(specs, s))
+	}
+
+	return Specifiers{
+		specifiers: sss,
+		conf:       ss.conf,
+	}, nil
+}
 
+// Simplify returns new specifiers with redundant bounds removed from each OR-group,
+// e.g. ">=1.0,>=1.5,<3.0,<2.0" becomes ">=1.5,<2.0".
+// A bound is removed only if another bound in the same group implies it,
+// so the result gives the same result as the original for any version.
+// The other operators are left as-is.
+func (ss Specifiers) Simplify() Specifiers {
+	var sss [][]specifier
+	for _, orS := range ss.specifiers {
+		var specs []specifier
+		for i, b := range orS {
+			redundant := false
+			for j, a := range orS {
+				if i == j || !implies(a, b) {
+					continue
+				}
+				// Keep the first one if they imply each other
+				if implies(b, a) && i < j {
+					continue
+				}
+				redundant = true
+				break
+			}
+			if !redundant {
+				specs = append(specs, b)
+			}
+		}
+		sss = append(sss, specs)
+	}
+
+	return Specifiers{
+		specifiers: sss,
+		conf:       ss.conf,
+	}
+}
+
+// implies tests if any version satisfying a satisfies b as well.
+// Since ">" and "<" exclude post-releases, pre-releases and local versions of the version in
+// the specifier, they are implied by another bound only if the base versions are different.
+func implies(a, b specifier) bool {
+	isLower := func(s specifier) bool { return s.op == ">" || s.op == ">=" }
+	isUpper := func(s specifier) bool { return s.op == "<" || s.op == "<=" }
+
+	switch {
+	case isLower(b) && isLower(a):
+	case isUpper(b) && isUpper(a):
+	default:
+		return false
+	}
+
+	av, bv := a.parsed, b.parsed
+	baseCompare := MustParse(av.BaseVersion()).Compare(MustParse(bv.BaseVersion()))
+
+	switch b.op {
+	case ">=":
+		return av.GreaterThanOrEqual(bv)
+	case ">":
+		return baseCompare > 0 || (a.op == ">" && av.Equal(bv))
+	case "<=":
+		return av.LessThanOrEqual(bv)
+	case "<":
+		return baseCompare < 0 || (a.op == "<" && av.Equal(bv))
+	}
 	return false
 }
 
+// single returns the specifier if there is only one.
+// A negated specifier returned by Negate, such as "not <2.0", has no PEP 440 operator and is not returned,
+// so the accessors of a single clause such as AnchorVersion and HasArbitraryPin report false for it.
+func (ss Specifiers) single() (specifier, bool) {
+	if len(ss.specifiers) != 1 || len(ss.specifiers[0]) != 1 || ss.specifiers[0][0].negated {
+		return specifier{}, false
+	}
+	return ss.specifiers[0][0], true
+}
+
+// ClauseResult is the result of checking a version against a single specifier.
+type ClauseResult struct {
+	// Branch is the index of the OR-group the specifier belongs to.
+	Branch   int
+	Operator string
+	Version  string
+	Passed   bool
+}
+
+// Explain checks a version against every specifier and returns the results grouped by OR-group.
+// The version satisfies the specifiers if all the specifiers in any group passed, as in Check.
+func (ss Specifiers) Explain(v Version) []ClauseResult {
+	if ss.conf.includePreRelease {
+		v.preReleaseIncluded = true
+	}
+
+	var results []ClauseResult
+	for i, orS := range ss.specifiers {
+		for _, andS := range orS {
+			results = append(results, ClauseResult{
+				Branch:   i,
+				Operator: andS.operatorString(),
+				Version:  andS.version,
+				Passed:   andS.check(v),
+			})
+		}
+	}
+	return results
+}
+
+// CheckWithReason is like Check but also returns why the version doesn't satisfy the specifiers.
+// The reason names the first specifier that rejected the version in the first OR-group,
+// e.g. "failed >=2.0 in branch 1", where branches are numbered from 1.
+// The reason is empty if the version satisfies the specifiers.
+func (ss Specifiers) CheckWithReason(v Version) (bool, string) {
+	if ss.conf.includePreRelease {
+		v.preReleaseIncluded = true
+	}
+
+	var reason string
+	for i, orS := range ss.specifiers {
+		failed := -1
+		for j, andS := range orS {
+			if !andS.check(v) {
+				failed = j
+				break
+			}
+		}
+		if failed < 0 {
+			return true, ""
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("failed %s%s in branch %d", orS[failed].operatorString(), orS[failed].version, i+1)
+		}
+	}
+
+	if reason == "" {
+		reason = "no specifiers"
+	}
+	return false, reason
+}
+
 func (s specifier) check(v Version) bool {
-	return s.operator(v, s.version)
+	return s.operator(v, s)
 }
 
+// operatorString returns the operator, reporting the operator-less form as "==".
+func (s specifier) operatorString() string {
+	if s.op == "" {
+		return "=="
+	}
+	return s.op
+}
+
+// String returns the specifier as written without the whitespace between the operator and the version,
+// e.g. ">= 2.0" is ">=2.0".
 func (s specifier) String() string {
-	return s.original
+	return s.op + strings.TrimSpace(strings.TrimPrefix(s.original, s.op))
 }
 
-// String returns the string format of the specifiers
+// String returns the string format of the specifiers.
+// The whitespace in the input is removed, so ">= 1.0, < 2.0" and ">=1.0,<2.0" give the same string,
+// but the versions are not normalized; use Originals for the specifiers as written.
 func (ss Specifiers) String() string {
 	var ssStr []string
 	for _, orS := range ss.specifiers {
+		if len(orS) == 0 {
+			ssStr = append(ssStr, "*")
+			continue
+		}
+
 		var sstr []string
 		for _, andS := range orS {
 			sstr = append(sstr, andS.String())
@@ -208,6 +1043,44 @@ func (ss Specifiers) String() string {
 	return strings.Join(ssStr, "||")
 }
 
+// MarshalYAML implements the yaml.Marshaler interface.
+// The specifiers are encoded as a string. An error is returned for the negated specifiers
+// returned by Negate, such as "not <2.0", since they cannot be parsed back.
+func (ss Specifiers) MarshalYAML() (interface{}, error) {
+	for _, orS := range ss.specifiers {
+		for _, andS := range orS {
+			if andS.negated {
+				return nil, xerrors.Errorf("negated specifier cannot be encoded: %s", andS)
+			}
+		}
+	}
+	return ss.String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+// It decodes a string and parses it with NewSpecifiers, and the errors include the line of the value.
+// An empty string or null, which MarshalYAML gives for the zero value, decodes to the zero value.
+// Options such as WithPreRelease are not encoded and must be applied after decoding.
+func (ss *Specifiers) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return xerrors.Errorf("line %d: specifiers must be a string: %w", value.Line, err)
+	}
+	if s == "" {
+		*ss = Specifiers{}
+		return nil
+	}
+
+	specifiers, err := NewSpecifiers(s)
+	if err != nil {
+		return xerrors.Errorf("line %d: failed to parse specifiers (%s): %w", value.Line, s, err)
+	}
+	*ss = specifiers
+	return nil
+}
+
+// andCheck tests if the version satisfies all the specifiers, stopping at the first failure.
+// The specifiers are checked in the given order.
 func andCheck(v Version, specifiers []specifier) bool {
 	for _, c := range specifiers {
 		if !c.check(v) {
@@ -217,8 +1090,15 @@ func andCheck(v Version, specifiers []specifier) bool {
 	return true
 }
 
+// versionSplit splits the version into its components like _version_split in pypa/packaging.
+// The first component is always the epoch so that prefix matching doesn't mix up epochs.
 func versionSplit(version string) []string {
-	var result []string
+	epoch := "0"
+	if i := strings.LastIndex(version, "!"); i >= 0 {
+		epoch, version = version[:i], version[i+1:]
+	}
+
+	result := []string{epoch}
 	for _, v := range strings.Split(version, ".") {
 		m := prefixRegexp.FindStringSubmatch(v)
 		if m != nil {
@@ -230,6 +1110,11 @@ func versionSplit(version string) []string {
 	return result
 }
 
+// versionJoin is the inverse of versionSplit.
+func versionJoin(components []string) string {
+	return components[0] + "!" + strings.Join(components[1:], ".")
+}
+
 func isDigist(s string) bool {
 	if _, err := strconv.Atoi(s); err == nil {
 		return true
@@ -253,7 +1138,7 @@ func padVersion(left, right []string) ([]string, []string) {
 
 	// Get the rest of our versions
 	leftRest := left[len(leftRelease):]
-	rightRest := left[len(rightRelease):]
+	rightRest := right[len(rightRelease):]
 
 	for i := 0; i < len(leftRelease)-len(rightRelease); i++ {
 		rightRelease = append(rightRelease, "0")
@@ -269,13 +1154,23 @@ func padVersion(left, right []string) ([]string, []string) {
 // Specifier functions
 //-------------------------------------------------------------------
 
-func specifierCompatible(prospective Version, spec string) bool {
+func specifierCompatible(prospective Version, spec specifier) bool {
 	// Compatible releases have an equivalent combination of >= and ==. That is that ~=2.2 is equivalent to >=2.2,==2.*.
 	// This allows us to implement this in terms of the other specifiers instead of implementing it ourselves.
 	// The only thing we need to do is construct the other specifiers.
 
+	prefix := versionJoin(compatiblePrefix(spec.parsed))
+
+	// Add the prefix notation to the end of our string
+	prefix += ".*"
+
+	return specifierGreaterThanEqual(prospective, spec) && specifierEqual(prospective, specifier{version: prefix})
+}
+
+// compatiblePrefix returns the components of the prefix that "~=" requires, starting with the epoch.
+func compatiblePrefix(v Version) []string {
 	var prefixElements []string
-	for _, s := range versionSplit(spec) {
+	for _, s := range versionSplit(v.String()) {
 		if strings.HasPrefix(s, "post") || strings.HasPrefix(s, "dev") {
 			break
 		}
@@ -284,24 +1179,19 @@ func specifierCompatible(prospective Version, spec string) bool {
 
 	// We want everything but the last item in the version, but we want to ignore post and dev releases and
 	// we want to treat the pre-release as it's own separate segment.
-	prefix := strings.Join(prefixElements[:len(prefixElements)-1], ".")
-
-	// Add the prefix notation to the end of our string
-	prefix += ".*"
-
-	return specifierGreaterThanEqual(prospective, spec) && specifierEqual(prospective, prefix)
+	return prefixElements[:len(prefixElements)-1]
 }
 
-func specifierEqual(prospective Version, spec string) bool {
+func specifierEqual(prospective Version, spec specifier) bool {
 	// https://github.com/pypa/packaging/blob/a6407e3a7e19bd979e93f58cfc7f6641a7378c46/packaging/specifiers.py#L476
 	// We need special logic to handle prefix matching
-	if strings.HasSuffix(spec, ".*") {
+	if strings.HasSuffix(spec.version, ".*") {
 		// In the case of prefix matching we want to ignore local segment.
-		prospective = MustParse(prospective.Public())
+		prospective = prospective.withoutLocal()
 
 		// Split the spec out by dots, and pretend that there is an implicit
 		// dot in between a release segment and a pre-release segment.
-		splitSpec := versionSplit(strings.TrimSuffix(spec, ".*"))
+		splitSpec := versionSplit(strings.TrimSuffix(spec.version, ".*"))
 
 		// Split the prospective version out by dots, and pretend that there is an implicit dot
 		//  in between a release segment and a pre-release segment.
@@ -318,21 +1208,22 @@ func specifierEqual(prospective Version, spec string) bool {
 		return reflect.DeepEqual(paddedSpec, paddedProspective)
 	}
 
-	specVersion := MustParse(spec)
+	// If the spec doesn't have a local segment, the local segment of the prospective version is ignored.
+	// Otherwise, the local segments must be the same, e.g. ==1.0+abc doesn't match 1.0+abcd.
+	specVersion := spec.parsed
 	if specVersion.local == "" {
-		prospective = MustParse(prospective.Public())
+		prospective = prospective.withoutLocal()
 	}
 
 	return specVersion.Equal(prospective)
 }
 
-func specifierNotEqual(prospective Version, spec string) bool {
+func specifierNotEqual(prospective Version, spec specifier) bool {
 	return !specifierEqual(prospective, spec)
 }
 
-func specifierLessThan(prospective Version, spec string) bool {
-	// Convert our spec to a Version instance, since we'll want to work with it as a version.
-	s := MustParse(spec)
+func specifierLessThan(prospective Version, spec specifier) bool {
+	s := spec.parsed
 
 	// Check to see if the prospective version is less than the spec version.
 	// If it's not we can short circuit and just return False now instead of doing extra unneeded work.
@@ -351,9 +1242,8 @@ func specifierLessThan(prospective Version, spec string) bool {
 	return true
 }
 
-func specifierGreaterThan(prospective Version, spec string) bool {
-	// Convert our spec to a Version instance, since we'll want to work with it as a version.
-	s := MustParse(spec)
+func specifierGreaterThan(prospective Version, spec specifier) bool {
+	s := spec.parsed
 
 	// Check to see if the prospective version is greater than the spec version.
 	// If it's not we can short circuit and just return False now instead of doing extra unneeded work.
@@ -380,18 +1270,16 @@ func specifierGreaterThan(prospective Version, spec string) bool {
 	return true
 }
 
-func specifierArbitrary(prospective Version, spec string) bool {
-	return strings.EqualFold(prospective.String(), spec)
+func specifierArbitrary(prospective Version, spec specifier) bool {
+	return strings.EqualFold(prospective.String(), spec.version)
 }
 
-func specifierLessThanEqual(prospective Version, spec string) bool {
-	p := MustParse(prospective.Public())
-	s := MustParse(spec)
-	return p.LessThanOrEqual(s)
+func specifierLessThanEqual(prospective Version, spec specifier) bool {
+	p := prospective.withoutLocal()
+	return p.LessThanOrEqual(spec.parsed)
 }
 
-func specifierGreaterThanEqual(prospective Version, spec string) bool {
-	p := MustParse(prospective.Public())
-	s := MustParse(spec)
-	return p.GreaterThanOrEqual(s)
+func specifierGreaterThanEqual(prospective Version, spec specifier) bool {
+	p := prospective.withoutLocal()
+	return p.GreaterThanOrEqual(spec.parsed)
 }
@@ -2,10 +2,13 @@ package version
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewConstraints(t *testing.T) {
@@ -31,6 +34,11 @@ func TestNewConstraints(t *testing.T) {
 		// Operator-less specifier
 		{"2.0", false}, // go-pep-440-version permits this case
 
+		// Any version
+		{"*", false},
+		{"", true},
+		{" ", true},
+
 		// Invalid operator
 		{"=>2.0", true},
 
@@ -82,6 +90,284 @@ func TestNewConstraints(t *testing.T) {
 	}
 }
 
+func TestNewSpecifiers_ErrorPosition(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantClause int
+		wantOffset int
+	}{
+		{">=1.0,foo,<2.0", 1, 6},
+		{">=1.0, foo, <2.0", 1, 7},
+		{"foo", 0, 0},
+		{"  =>2.0", 0, 2},
+		{">=1.0 <2.0, foo", 2, 12},
+		{">=1.0 <2.0 foo", 2, 11},
+		{">=1.0foo,<2.0", 0, 0},
+		{">=1.0,<2.0foo", 1, 6},
+		{">=1.0,,<2.0", 1, 6},
+		{">=1.0 || <2.0,foo", 2, 14},
+		{"* || foo", 1, 5},
+		{">=1.0 ||", 1, 8},
+		{">=1.0 || ~=1", 1, 9},
+		{">=1.0, >=1.0+local", 1, 7},
+		{"==1.0, ==1.0+K", 1, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := NewSpecifiers(tt.spec)
+			require.Error(t, err)
+
+			var perr *ConstraintParseError
+			require.True(t, xerrors.As(err, &perr))
+			assert.Equal(t, tt.wantClause, perr.Clause)
+			assert.Equal(t, tt.wantOffset, perr.Offset)
+			assert.Contains(t, err.Error(), fmt.Sprintf("(clause %d at offset %d)", tt.wantClause, tt.wantOffset))
+		})
+	}
+}
+
+func TestConstraintParseError_Wrappers(t *testing.T) {
+	tests := []struct {
+		name       string
+		parse      func() error
+		wantOffset int
+	}{
+		{
+			name: "separators",
+			parse: func() error {
+				_, err := NewSpecifiersWithSeparators(">=1.0 or ==foo", " or ", ";")
+				return err
+			},
+			wantOffset: 9,
+		},
+		{
+			name: "separators within a group",
+			parse: func() error {
+				_, err := NewSpecifiersWithSeparators(">=1.0 and <2.0 and foo", " or ", " and ")
+				return err
+			},
+			wantOffset: 19,
+		},
+		{
+			name: "separators with an empty group",
+			parse: func() error {
+				_, err := NewSpecifiersWithSeparators(">=1.0 or ", " or ", ";")
+				return err
+			},
+			wantOffset: 9,
+		},
+		{
+			name: "npm",
+			parse: func() error {
+				_, err := NewSpecifiersNpm("^1.2, foo")
+				return err
+			},
+			wantOffset: 6,
+		},
+		{
+			name: "npm after a range",
+			parse: func() error {
+				_, err := NewSpecifiersNpm("~1.2.3 || >=2.0, foo")
+				return err
+			},
+			wantOffset: 17,
+		},
+		{
+			name: "requirement",
+			parse: func() error {
+				_, _, err := ParseRequirement("requests>=1.0,foo")
+				return err
+			},
+			wantOffset: 14,
+		},
+		{
+			name: "requirement in parentheses",
+			parse: func() error {
+				_, _, err := ParseRequirement("  requests[security] ( >=1.0, foo)")
+				return err
+			},
+			wantOffset: 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse()
+			require.Error(t, err)
+
+			var perr *ConstraintParseError
+			require.True(t, xerrors.As(err, &perr))
+			assert.Equal(t, tt.wantOffset, perr.Offset)
+		})
+	}
+}
+
+func TestValidConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       bool
+	}{
+		{">=1.0, <2.0", true},
+		{">= 1.0 != 1.3.4.* < 2.0", true},
+		{"==2.1.* || ~=3.1", true},
+		{"2.0", true},
+		{"*", true},
+		{"* || >1.0", true},
+		{"~=1", true}, // valid syntax, rejected by NewSpecifiers
+		{"", false},
+		{"=>2.0", false},
+		{">=1.0 ||", false},
+		{"french toast", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidConstraint(tt.constraint))
+		})
+	}
+}
+
+func TestNewSpecifiers_OperatorLess(t *testing.T) {
+	sample, err := ParseList([]string{"1.2", "1.2.3", "1.2.3.0", "1.2.3+local", "1.2.3rc1", "1.2.4", "2.0"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec  string
+		equal string
+	}{
+		{"1.2.3", "==1.2.3"},
+		{" 1.2.3 ", "==1.2.3"},
+		{"1.2.*", "==1.2.*"},
+		{"1.2.3, <2", "==1.2.3, <2"},
+		{"1.2 || 2.0", "==1.2 || ==2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, "==", c.Operators()[0])
+
+			want, err := NewSpecifiers(tt.equal)
+			require.NoError(t, err)
+			assert.True(t, c.Equivalent(want, sample))
+		})
+	}
+
+	// A malformed version is still an error rather than an operator-less specifier.
+	for _, spec := range []string{"1.2.3.", "foo", "1.2.3 bar"} {
+		_, err := NewSpecifiers(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewSpecifiersWithSeparators(t *testing.T) {
+	tests := []struct {
+		spec    string
+		or      string
+		and     string
+		want    string
+		wantErr bool
+	}{
+		{">=1.0; <2.0 or ==3.0", " or ", ";", ">=1.0,<2.0||==3.0", false},
+		{">=1.0 and <2.0 | ==3.0.post1", "|", " and ", ">=1.0,<2.0||==3.0.post1", false},
+		{">=1.0, <2.0 || ==3.0", "||", ",", ">=1.0,<2.0||==3.0", false},
+		{">=1.0;<2.0", " or ", ";", ">=1.0,<2.0", false},
+		{">=1.0; =>2.0", " or ", ";", "", true},
+		{">=1.0", "", ";", "", true},
+		{">=1.0", ";", ";", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := NewSpecifiersWithSeparators(tt.spec, tt.or, tt.and)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+
+			// The default separators are unchanged
+			want, err := NewSpecifiers(tt.want)
+			require.NoError(t, err)
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
+
+func TestNewSpecifiersAll(t *testing.T) {
+	specs, errs := NewSpecifiersAll([]string{">=1.0", "=>2.0", "~=1.4.2", "==1.0.*+5"})
+	require.Len(t, specs, 4)
+	require.Len(t, errs, 4)
+
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.Error(t, errs[3])
+
+	assert.Equal(t, ">=1.0", specs[0].String())
+	assert.Equal(t, "~=1.4.2", specs[2].String())
+
+	specs, errs = NewSpecifiersAll(nil)
+	assert.Empty(t, specs)
+	assert.Empty(t, errs)
+}
+
+func TestNewSpecifier(t *testing.T) {
+	tests := []struct {
+		operator string
+		version  string
+		want     string
+		match    string
+		wantErr  bool
+	}{
+		{">=", "1.0", ">=1.0", "1.5", false},
+		{"==", "1.0.*", "==1.0.*", "1.0.3", false},
+		{"~=", " 2.2 ", "~=2.2", "2.9", false},
+		{"===", "1.0", "===1.0", "1.0", false},
+		{"=>", "1.0", "", "", true},
+		{"~=", "1", "", "", true},
+		{">=", "1.0, <2.0", "", "", true},
+		{"===", "foo bar", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operator+tt.version, func(t *testing.T) {
+			got, err := NewSpecifier(tt.operator, tt.version)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Check(MustParse(tt.match)))
+		})
+	}
+}
+
+func TestExcludeVersions(t *testing.T) {
+	excluded, err := ParseList([]string{"1.0", "1.2rc1", "1!2.0.post1+local"})
+	require.NoError(t, err)
+
+	c, err := ExcludeVersions(excluded)
+	require.NoError(t, err)
+	assert.Equal(t, "!=1.0,!=1.2rc1,!=1!2.0.post1+local", c.String())
+
+	for _, v := range excluded {
+		assert.False(t, c.Check(v), v.String())
+	}
+	for _, v := range []string{"1.0.0", "1.0+local"} {
+		assert.False(t, c.Check(MustParse(v)), v)
+	}
+	for _, v := range []string{"0.9", "1.0.post1", "1.1", "1.2rc1.dev0", "1.2rc2", "1.2", "1!2.0.post1"} {
+		assert.True(t, c.Check(MustParse(v)), v)
+	}
+
+	c, err = ExcludeVersions(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "*", c.String())
+	assert.True(t, c.Check(MustParse("1.0")))
+
+	_, err = ExcludeVersions([]Version{{}})
+	assert.Error(t, err)
+}
+
 func TestVersion_Check(t *testing.T) {
 	tests := []struct {
 		version string
@@ -127,6 +413,12 @@ func TestVersion_Check(t *testing.T) {
 		// Test the in-equality operation with a prefix
 		{"2.0", "!=3.*", true},
 		{"2.1", "!=2.0.*", true},
+		{"1.5", "!=1.4.*", true},
+		{"1.40", "!=1.4.*", true},
+		{"1.40.0", "!=1.4.*", true},
+		{"1.3.9", "!=1.4.*", true},
+		{"1!1.4", "!=1.4.*", true},
+		{"1.5.0.dev1", "!=1.4.*", true},
 
 		// Test the greater than equal operation
 		{"2.0", ">=2", true},
@@ -178,6 +470,9 @@ func TestVersion_Check(t *testing.T) {
 		{"2!1.0", ">=2.0", true},
 		{"1.0", "<2!0.1", true},
 		{"2!1.0", ">2.0", true},
+		{"1!2.2", "~=1!2.2", true},
+		{"1!2.9", "~=1!2.2", true},
+		{"1!2.2.1", "~=1!2.2.0", true},
 
 		// Test some normalization rules
 		{"2.0.5", ">2.0dev", true},
@@ -218,6 +513,16 @@ func TestVersion_Check(t *testing.T) {
 		{"2.0.0", "!=2.*", false},
 		{"2.0.post1", "!=2.0.post1.*", false},
 		{"2.0.post1.dev1", "!=2.0.post1.*", false},
+		{"1.4", "!=1.4.*", false},
+		{"1.4.0", "!=1.4.*", false},
+		{"1.4.9", "!=1.4.*", false},
+		{"1.4rc1", "!=1.4.*", false},
+		{"1.4.0.post1", "!=1.4.*", false},
+		{"1.4.9+local", "!=1.4.*", false},
+		{"1.04.2", "!=1.4.*", false},
+		{"1.4.2", "!=1.04.*", false},
+		{"1!1.4.2", "!=01!1.4.*", false},
+		{"1.4rc1", "!=1.4-rc1.*", false},
 
 		//Test the greater than equal operation
 		{"2.0.dev1", ">=2", false},
@@ -277,6 +582,12 @@ func TestVersion_Check(t *testing.T) {
 		{"2!1.0", "==1.*", false},
 		{"1.0", "==2!1.*", false},
 		{"2!1.0", "!=2!1.0", false},
+		{"1!3.0", "~=1!2.2", false},
+		{"1!2.1", "~=1!2.2", false},
+		{"2.5", "~=1!2.2", false},
+		{"2!2.5", "~=1!2.2", false},
+		{"1!2.3.0", "~=1!2.2.0", false},
+		{"2.0", "==2!2.*", false},
 
 		// local versions
 		{"1.0.0+local", "==1.0.0", true},
@@ -285,6 +596,15 @@ func TestVersion_Check(t *testing.T) {
 		{"1.0.0+local", ">=1.0.0", true},
 		{"1.0.0+local", "<1.0.0", false},
 		{"1.0.0+local", ">1.0.0", false},
+		{"1.0+abc", "==1.0+abc", true},
+		{"1.0+ABC", "==1.0+abc", true},
+		{"1.0+abc", "==1.0", true},
+		{"1.0+anything", "==1.0", true},
+		{"1.0+abcd", "==1.0+abc", false},
+		{"1.0+ab", "==1.0+abc", false},
+		{"1.0+abc.1", "==1.0+abc", false},
+		{"1.0", "==1.0+abc", false},
+		{"1.0+abcd", "!=1.0+abc", true},
 
 		// and operators
 		{"1.0", ">= 1.0, != 1.3.4.*, < 2.0", true},
@@ -313,6 +633,10 @@ func TestVersion_Check(t *testing.T) {
 		{"2.1", "=2.0.0", false},
 		{"2.0", "=2.0+deadbeef", false},
 		{"2.0", "*", true},
+		{"0.0.0.dev0", "*", true},
+		{"1!1.0rc1+local", "*", true},
+		{"1.0", " * ", true},
+		{"1.0", "<1.0 || *", true},
 
 		// space separated
 		{"1.0", ">= 1.0 != 1.3.4.* < 2.0", true},
@@ -356,3 +680,1004 @@ func TestVersion_CheckWithPreRelease(t *testing.T) {
 		})
 	}
 }
+
+func TestVersion_CheckEpochPrefix(t *testing.T) {
+	tests := []struct {
+		version string
+		spec    string
+		want    bool
+	}{
+		{"1!1.0", "==1!1.*", true},
+		{"1!1.5.2", "==1!1.*", true},
+		{"1!1.0rc1", "==1!1.*", true},
+		{"01!1.5", "==1!1.*", true},
+		{"1!1.5", "==01!1.*", true},
+		{"1.0", "==1!1.*", false},
+		{"1.5", "==1!1.*", false},
+		{"2!1.5", "==1!1.*", false},
+		{"1!2.0", "==1!1.*", false},
+		{"1!2.0", "==2.*", false},
+		{"2.0", "==2.*", true},
+		{"0!2.0", "==2.*", true},
+		{"2.0", "==0!2.*", true},
+		{"1.5", "!=1!1.*", true},
+		{"1!1.5", "!=1!1.*", false},
+		{"1!2.0", "!=2.*", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.version, tt.spec), func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			v, err := Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Check(v))
+		})
+	}
+}
+
+func TestVersion_CheckEpochExclusive(t *testing.T) {
+	tests := []struct {
+		version string
+		spec    string
+		want    bool
+	}{
+		{"1!2.0", ">1!1.0", true},
+		{"1!1.1", ">1!1.0", true},
+		{"2!0.1", ">1!1.0", true},
+		{"999.0", ">1!1.0", false},
+		{"1!1.0", ">1!1.0", false},
+		{"1!1.0.post1", ">1!1.0", false},
+		{"1!1.0+local", ">1!1.0", false},
+		{"1!1.0.post2", ">1!1.0.post1", true},
+		{"1!0.1", ">1.0", true},
+		{"999.0", "<1!1.0", true},
+		{"1!0.9", "<1!1.0", true},
+		{"2!0.1", "<1!1.0", false},
+		{"1!1.0rc1", "<1!1.0", false},
+		{"1!5.0", "<2!1.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.version, tt.spec), func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			v, err := Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Check(v))
+		})
+	}
+}
+
+func TestVersion_CheckCaseFolding(t *testing.T) {
+	tests := []struct {
+		version string
+		spec    string
+		want    bool
+	}{
+		{"1.0RC1", "==1.0rc1", true},
+		{"1.0RC1", "===1.0rc1", true},
+		{"1.0+ABC", "===1.0+abc", true},
+		{"1.0.POST1", "===1.0.post1", true},
+		{"1.0-R1", "===1.0.post1", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.version, tt.spec), func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			v, err := Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Check(v))
+		})
+	}
+
+	// The arbitrary equality compares ASCII letters case-insensitively.
+	assert.True(t, specifierArbitrary(MustParse("1.0rc1+abc"), specifier{version: "1.0RC1+ABC"}))
+	assert.False(t, specifierArbitrary(MustParse("1.0.post0"), specifier{version: "1.0.POST"}))
+
+	// Non-ASCII letters are rejected rather than case-folded, e.g. the long s for "s".
+	for _, spec := range []string{"===1.0.poſt0", ">=1.0.poſt1", "==1.0+K"} {
+		_, err := NewSpecifiers(spec)
+		assert.Error(t, err, spec)
+		assert.False(t, ValidConstraint(spec), spec)
+	}
+}
+
+func TestSpecifiers_FilterStable(t *testing.T) {
+	versions := []string{"0.9", "1.0rc1", "1.0", "1.1.dev1", "1.1", "2.0a1", "2.0"}
+	tests := []struct {
+		name     string
+		spec     string
+		allowPre bool
+		want     []string
+	}{
+		{
+			name: "pre-releases excluded by default",
+			spec: ">=1.0",
+			want: []string{"1.0", "1.1", "2.0"},
+		},
+		{
+			name: "pre-release named in the specifier",
+			spec: ">=1.0rc1, <1.5",
+			want: []string{"1.0rc1", "1.0", "1.1.dev1", "1.1"},
+		},
+		{
+			name:     "pre-releases allowed",
+			spec:     ">=1.0, <1.5",
+			allowPre: true,
+			want:     []string{"1.0", "1.1.dev1", "1.1"},
+		},
+		{
+			name: "nothing matches",
+			spec: ">3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+			c = c.AllowPreReleases(tt.allowPre)
+
+			vs, err := ParseList(versions)
+			require.NoError(t, err)
+
+			var got []string
+			for _, v := range c.FilterStable(vs) {
+				got = append(got, v.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpecifiers_Equivalent(t *testing.T) {
+	sample, err := ParseList([]string{"0.9", "1.0", "1.2", "1.5", "1.5.1", "1.9", "2.0", "2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec1 string
+		spec2 string
+		want  bool
+	}{
+		{">=1.0,>=1.5", ">=1.5", true},
+		{">=1.0,<2.0", "==1.*", true},
+		{"~=1.5", ">=1.5,<2.0", true},
+		{">=1.0,<2.0", ">=1.0,<=2.0", false},
+		{"==1.5", "==1.5.*", false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.spec1, tt.spec2), func(t *testing.T) {
+			c1, err := NewSpecifiers(tt.spec1)
+			require.NoError(t, err)
+
+			c2, err := NewSpecifiers(tt.spec2)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c1.Equivalent(c2, sample))
+			assert.Equal(t, tt.want, c2.Equivalent(c1, sample))
+		})
+	}
+}
+
+func TestSpecifiers_HasExactPin(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantPin    string
+		wantPinned bool
+		wantArb    string
+		wantArbPin bool
+	}{
+		{spec: "==1.2.3", wantPin: "1.2.3", wantPinned: true},
+		{spec: "1.2.3", wantPin: "1.2.3", wantPinned: true},
+		{spec: "=1.2.3+local", wantPin: "1.2.3+local", wantPinned: true},
+		{spec: "==1.2.*"},
+		{spec: ">=1.2.3"},
+		{spec: "==1.2.3, !=1.2.4"},
+		{spec: "==1.2.3 || ==1.2.4"},
+		{spec: "===1.2.3", wantArb: "1.2.3", wantArbPin: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			pin, ok := c.HasExactPin()
+			assert.Equal(t, tt.wantPinned, ok)
+			if ok {
+				assert.Equal(t, tt.wantPin, pin.String())
+			}
+
+			arb, ok := c.HasArbitraryPin()
+			assert.Equal(t, tt.wantArbPin, ok)
+			assert.Equal(t, tt.wantArb, arb)
+		})
+	}
+}
+
+func TestSpecifiers_AnchorVersion(t *testing.T) {
+	tests := []struct {
+		spec   string
+		want   string
+		wantOK bool
+	}{
+		{spec: "~=2.2", want: "2.2", wantOK: true},
+		{spec: ">=2.2", want: "2.2", wantOK: true},
+		{spec: "> 2.2", want: "2.2", wantOK: true},
+		{spec: "<=2.2.post1", want: "2.2.post1", wantOK: true},
+		{spec: "<2.2rc1", want: "2.2rc1", wantOK: true},
+		{spec: "==2.2", want: "2.2", wantOK: true},
+		{spec: "2.2", want: "2.2", wantOK: true},
+		{spec: "!=2.2", want: "2.2", wantOK: true},
+		{spec: "==2.02.*", want: "2.2", wantOK: true},
+		{spec: "===2.2", want: "2.2", wantOK: true},
+		{spec: ">=2.2,<3.0"},
+		{spec: ">=2.2 || <1.0"},
+		{spec: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			got, ok := c.AnchorVersion()
+			assert.Equal(t, tt.wantOK, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+
+	// The negated specifiers have no anchor.
+	for _, spec := range []string{"===1.0", "<2.0", ">2.0"} {
+		c, err := NewSpecifiers(spec)
+		require.NoError(t, err)
+
+		negated := c.Negate()
+		_, ok := negated.AnchorVersion()
+		assert.False(t, ok, spec)
+		_, ok = negated.HasArbitraryPin()
+		assert.False(t, ok, spec)
+	}
+}
+
+func TestSpecifiers_Operators(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"==1.2.3", []string{"=="}},
+		{"1.2.3", []string{"=="}},
+		{">=1.0, <2.0, !=1.5 || >=3.0, ~=3.1", []string{">=", "<", "!=", "~="}},
+		{"*", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Operators())
+		})
+	}
+
+	t.Run("negated", func(t *testing.T) {
+		c, err := NewSpecifiers(">=1.0, <2.0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"<=", "!="}, c.Negate().Operators())
+	})
+}
+
+func TestSpecifiers_Originals(t *testing.T) {
+	tests := []struct {
+		spec string
+		want [][]string
+	}{
+		{"==1.2.3", [][]string{{"==1.2.3"}}},
+		{"  1.02.3  ", [][]string{{"1.02.3"}}},
+		{">= 1.0 ,<2.0, != 1.5.* || ~= 3.1.0", [][]string{{">= 1.0", "<2.0", "!= 1.5.*"}, {"~= 3.1.0"}}},
+		{">=v1.0a1 || *", [][]string{{">=v1.0a1"}, {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.Originals())
+		})
+	}
+}
+
+func TestSpecifiers_StringWhitespace(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{">= 2.0", ">=2.0"},
+		{">=2.0", ">=2.0"},
+		{"  >=   2.0  ", ">=2.0"},
+		{">= 1.0 ,<2.0, != 1.5.* || ~= 3.1.0", ">=1.0,<2.0,!=1.5.*||~=3.1.0"},
+		{"=== 1.0", "===1.0"},
+		{"  1.02.3  ", "1.02.3"},
+		{"* || > 1.0", "*||>1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.String())
+
+			// The result can be parsed back to the same string.
+			c, err = NewSpecifiers(c.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, c.String())
+		})
+	}
+
+	// The negation of "<" cannot be parsed back, so it cannot be encoded either.
+	c, err := NewSpecifiers("< 2.0")
+	require.NoError(t, err)
+	_, err = yaml.Marshal(c.Negate())
+	assert.Error(t, err)
+}
+
+func TestSpecifiers_IsSatisfiable(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{">=1.0, <2.0", true},
+		{">=1.0, <=1.0", true},
+		{">=2.0, <1.0", false},
+		{">1.0, <=1.0", false},
+		{">=1.0, <1.0", false},
+		{">=1.0, >=3.0, <2.0", false},
+		{"==1.0, ==2.0", false},
+		{"==1.0, ==1.0.0", true},
+		{"==1.0, ==1.0+abc", true},
+		{"==1.0, !=1.0", false},
+		{"==1.0, >1.0", false},
+		{"==1.5, >=1.0, <2.0", true},
+		{"==2.5, >=1.0, <2.0", false},
+		{">=2.0, <1.0 || ==1.5", true},
+		{">=2.0, <1.0 || ==1.0, !=1.0", false},
+		{"==1.*, !=1.*", true}, // not detected
+		{"*", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.IsSatisfiable())
+		})
+	}
+}
+
+func TestSpecifiers_YAML(t *testing.T) {
+	type config struct {
+		Name    string     `yaml:"name"`
+		Version Specifiers `yaml:"version"`
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "single clause",
+			input: "name: foo\nversion: \">=1.0\"\n",
+			want:  ">=1.0",
+		},
+		{
+			name:  "multiple clauses",
+			input: "name: foo\nversion: \">= 1.0, < 1.4 || > 2.0\"\n",
+			want:  ">=1.0,<1.4||>2.0",
+		},
+		{
+			name:    "invalid specifiers",
+			input:   "name: foo\nversion: \"=>1.0\"\n",
+			wantErr: "line 2: failed to parse specifiers (=>1.0)",
+		},
+		{
+			name:    "invalid specifiers after other lines",
+			input:   "# dependency\nname: foo\n\nversion: \">=1.0, <\"\n",
+			wantErr: "line 4: failed to parse specifiers (>=1.0, <)",
+		},
+		{
+			name:    "not a string",
+			input:   "name: foo\nversion: [1.0]\n",
+			wantErr: "line 2: specifiers must be a string",
+		},
+	}
+
+	// The zero value is encoded as an empty string and decoded back to the zero value.
+	for _, input := range []string{"", "version: \"\"\n", "version: null\n", "version:\n"} {
+		t.Run("zero value "+input, func(t *testing.T) {
+			var got config
+			require.NoError(t, yaml.Unmarshal([]byte(input), &got))
+			assert.Equal(t, Specifiers{}, got.Version)
+
+			b, err := yaml.Marshal(got)
+			require.NoError(t, err)
+
+			var roundTripped config
+			require.NoError(t, yaml.Unmarshal(b, &roundTripped))
+			assert.Equal(t, Specifiers{}, roundTripped.Version)
+		})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got config
+			err := yaml.Unmarshal([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Version.String())
+
+			// Round trip
+			b, err := yaml.Marshal(got)
+			require.NoError(t, err)
+
+			var roundTripped config
+			require.NoError(t, yaml.Unmarshal(b, &roundTripped))
+			assert.Equal(t, got.Version.String(), roundTripped.Version.String())
+			assert.True(t, roundTripped.Version.Check(MustParse("2.1")))
+		})
+	}
+}
+
+func TestSpecifiers_Explain(t *testing.T) {
+	c, err := NewSpecifiers(">= 1.0, < 1.4 || 2.1.*, !=2.1.3")
+	require.NoError(t, err)
+
+	got := c.Explain(MustParse("2.1.3"))
+	want := []ClauseResult{
+		{Branch: 0, Operator: ">=", Version: "1.0", Passed: true},
+		{Branch: 0, Operator: "<", Version: "1.4", Passed: false},
+		{Branch: 1, Operator: "==", Version: "2.1.*", Passed: true},
+		{Branch: 1, Operator: "!=", Version: "2.1.3", Passed: false},
+	}
+	assert.Equal(t, want, got)
+	assert.False(t, c.Check(MustParse("2.1.3")))
+
+	c, err = NewSpecifiers("<2", WithPreRelease(true))
+	require.NoError(t, err)
+	assert.Equal(t, []ClauseResult{{Branch: 0, Operator: "<", Version: "2", Passed: true}}, c.Explain(MustParse("2.0a1")))
+}
+
+func TestSpecifiers_CheckWithReason(t *testing.T) {
+	tests := []struct {
+		spec       string
+		version    string
+		want       bool
+		wantReason string
+	}{
+		{">=2.0", "1.0", false, "failed >=2.0 in branch 1"},
+		{">= 1.0, < 1.4 || 2.1.*, !=2.1.3", "2.1.3", false, "failed <1.4 in branch 1"},
+		{"2.1.* || >=1.0, <1.4", "2.2", false, "failed ==2.1.* in branch 1"},
+		{">= 1.0, < 1.4 || 2.1.*, !=2.1.3", "2.1.2", true, ""},
+		{"<2", "2.0a1", false, "failed <2 in branch 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.version, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			v := MustParse(tt.version)
+			got, reason := c.CheckWithReason(v)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantReason, reason)
+			assert.Equal(t, c.Check(v), got)
+		})
+	}
+
+	got, reason := Specifiers{}.CheckWithReason(MustParse("1.0"))
+	assert.False(t, got)
+	assert.Equal(t, "no specifiers", reason)
+}
+
+func TestSpecifiers_Bounds(t *testing.T) {
+	tests := []struct {
+		spec               string
+		wantLower          string
+		wantLowerInclusive bool
+		wantUpper          string
+		wantUpperInclusive bool
+		wantOk             bool
+	}{
+		{">=1.0", "1.0", true, "", false, true},
+		{">1.0", "1.0", false, "", false, true},
+		{"<=2.0", "", false, "2.0", true, true},
+		{"<2.0", "", false, "2.0", false, true},
+		{"==1.5", "1.5", true, "1.5", true, true},
+		{"1.5", "1.5", true, "1.5", true, true},
+		{"~=1.4.5", "1.4.5", true, "1.5", false, true},
+		{">=1.0, <2.0", "1.0", true, "2.0", false, true},
+		{">=1.0, >1.0, <=2.0, <3.0", "1.0", false, "2.0", true, true},
+		{">=1.0, ~=1.4.5, <1.4.9", "1.4.5", true, "1.4.9", false, true},
+		{">=1.0, ==1.5", "1.5", true, "1.5", true, true},
+		{"*", "", false, "", false, true},
+		{">=1.0 || <0.5", "", false, "", false, false},
+		{">=1.0, !=1.5", "", false, "", false, false},
+		{"==1.*", "", false, "", false, false},
+		{"===1.0", "", false, "", false, false},
+		{">=2.0, <1.0", "", false, "", false, false},
+		{">1.0, <=1.0", "", false, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			lower, lowerInclusive, upper, upperInclusive, ok := c.Bounds()
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantLower == "" {
+				assert.Nil(t, lower)
+			} else {
+				require.NotNil(t, lower)
+				assert.Equal(t, tt.wantLower, lower.String())
+			}
+			if tt.wantUpper == "" {
+				assert.Nil(t, upper)
+			} else {
+				require.NotNil(t, upper)
+				assert.Equal(t, tt.wantUpper, upper.String())
+			}
+			assert.Equal(t, tt.wantLowerInclusive, lowerInclusive)
+			assert.Equal(t, tt.wantUpperInclusive, upperInclusive)
+		})
+	}
+}
+
+func TestSpecifiers_And(t *testing.T) {
+	tests := []struct {
+		spec     string
+		clause   string
+		want     string
+		accepted []string
+		rejected []string
+		wantErr  bool
+	}{
+		{
+			spec:     ">=1.0",
+			clause:   "<2.0",
+			want:     ">=1.0,<2.0",
+			accepted: []string{"1.0", "1.9"},
+			rejected: []string{"0.9", "2.0", "2.1"},
+		},
+		{
+			spec:     ">=1.0||==0.9",
+			clause:   "!=1.3",
+			want:     ">=1.0,!=1.3||==0.9,!=1.3",
+			accepted: []string{"0.9", "1.0", "1.4"},
+			rejected: []string{"0.8", "1.3"},
+		},
+		{
+			spec:     "*",
+			clause:   "==1.*",
+			want:     "==1.*",
+			accepted: []string{"1.0", "1.9"},
+			rejected: []string{"2.0"},
+		},
+		{spec: ">=1.0", clause: "=>2.0", wantErr: true},
+		{spec: ">=1.0", clause: "<2.0,!=1.5", wantErr: true},
+		{spec: ">=1.0", clause: "*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.clause, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			got, err := c.And(tt.clause)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.spec, c.String(), "the original specifiers are unchanged")
+
+			for _, v := range tt.accepted {
+				assert.True(t, got.Check(MustParse(v)), v)
+			}
+			for _, v := range tt.rejected {
+				assert.False(t, got.Check(MustParse(v)), v)
+			}
+		})
+	}
+}
+
+func TestSpecifiers_Simplify(t *testing.T) {
+	sample, err := ParseList([]string{
+		"0.9", "1.0.dev1", "1.0a1", "1.0", "1.0+local", "1.0.post1", "1.0.post2", "1.2", "1.5rc1", "1.5",
+		"1.5+local", "1.5.post1", "1.9", "2.0.dev1", "2.0rc1", "2.0", "2.0+local", "2.0.post1", "2.5", "3.0",
+		"1!1.0",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{">=1.0,>=1.5,<3.0,<2.0", ">=1.5,<2.0"},
+		{">=1.5,>=1.0", ">=1.5"},
+		{">=1.0,>=1.0", ">=1.0"},
+		{">1.0,>=1.0", ">1.0"},
+		{">=1.0,>1.0", ">1.0"},
+		{">=1.5,>1.0", ">=1.5"},
+		{">1.0.post1,>1.0", ">1.0.post1,>1.0"},
+		{">=1.0.post1,>1.0", ">=1.0.post1,>1.0"},
+		{"<=2.0rc1,<2.0", "<=2.0rc1,<2.0"},
+		{"<=1.9,<2.0", "<=1.9"},
+		{"<2.0,<2.0", "<2.0"},
+		{"<2.0,<=2.0", "<2.0"},
+		{">=1.0,!=1.5,~=1.2,<3.0,<2.0", ">=1.0,!=1.5,~=1.2,<2.0"},
+		{">=1.0,>=1.5 || <3.0,<2.0", ">=1.5||<2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			got := c.Simplify()
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, c.Equivalent(got, sample))
+		})
+	}
+}
+
+func TestSpecifiers_CheckAll(t *testing.T) {
+	vs, err := ParseList([]string{"0.9", "1.0", "1.5", "2.0", "2.1"})
+	require.NoError(t, err)
+
+	c, err := NewSpecifiers(">= 1.0, < 1.4 || > 2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true, false, false, true}, c.CheckAll(vs))
+	assert.Equal(t, []bool{}, c.CheckAll(nil))
+}
+
+func TestSpecifiers_MatchingBranch(t *testing.T) {
+	tests := []struct {
+		version   string
+		spec      string
+		wantIndex int
+		wantOk    bool
+	}{
+		{"1.5", ">=1.0, <2.0 || >=2.0, <3.0 || ==4.*", 0, true},
+		{"2.5", ">=1.0, <2.0 || >=2.0, <3.0 || ==4.*", 1, true},
+		{"4.1", ">=1.0, <2.0 || >=2.0, <3.0 || ==4.*", 2, true},
+		{"3.5", ">=1.0, <2.0 || >=2.0, <3.0 || ==4.*", -1, false},
+		{"1.5", ">=1.0 || ~=1.4", 0, true},
+		{"0.5", "* || >=1.0", 0, true},
+		{"2.0rc1", "<1.0 || <2.0", -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.version, tt.spec), func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			v, err := Parse(tt.version)
+			require.NoError(t, err)
+
+			index, ok := c.MatchingBranch(v)
+			assert.Equal(t, tt.wantIndex, index)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, c.Check(v), ok)
+		})
+	}
+
+	index, ok := Specifiers{}.MatchingBranch(MustParse("1.0"))
+	assert.Equal(t, -1, index)
+	assert.False(t, ok)
+}
+
+func TestSpecifiers_CheckFunc(t *testing.T) {
+	vs, err := ParseList([]string{"0.9", "1.0rc1", "1.0", "1.5", "2.0"})
+	require.NoError(t, err)
+
+	// The same as slices.IndexFunc, which requires a newer Go version than this module.
+	indexFunc := func(vs []Version, f func(Version) bool) int {
+		for i, v := range vs {
+			if f(v) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	tests := []struct {
+		spec string
+		want int
+	}{
+		{">=1.0", 2},
+		{">=1.0rc1", 1},
+		{"==1.5.*", 3},
+		{">2.0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			f := c.CheckFunc()
+			assert.Equal(t, tt.want, indexFunc(vs, f))
+			for _, v := range vs {
+				assert.Equal(t, c.Check(v), f(v), v.String())
+			}
+		})
+	}
+}
+
+func TestSatisfiesAny_SatisfiesAll(t *testing.T) {
+	specs, errs := NewSpecifiersAll([]string{">=1.0", "<2.0", "~=1.4", "==3.0"})
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	ge10, lt20, compat14, eq30 := specs[0], specs[1], specs[2], specs[3]
+
+	tests := []struct {
+		name    string
+		version string
+		specs   []Specifiers
+		wantAny bool
+		wantAll bool
+	}{
+		{"all passing", "1.5", []Specifiers{ge10, lt20, compat14}, true, true},
+		{"mixed", "2.5", []Specifiers{ge10, lt20, compat14}, true, false},
+		{"only the last passing", "3.0", []Specifiers{lt20, compat14, eq30}, true, false},
+		{"all failing", "0.5", []Specifiers{ge10, compat14, eq30}, false, false},
+		{"none given", "1.0", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := MustParse(tt.version)
+			assert.Equal(t, tt.wantAny, SatisfiesAny(v, tt.specs...))
+			assert.Equal(t, tt.wantAll, SatisfiesAll(v, tt.specs...))
+		})
+	}
+}
+
+func TestSpecifiers_AnyMatch(t *testing.T) {
+	vs, err := ParseList([]string{"0.9", "1.0", "1.5"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{">=1.5", true},
+		{"<1.0", true},
+		{">1.5", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, c.AnyMatch(vs))
+			assert.False(t, c.AnyMatch(nil))
+		})
+	}
+}
+
+func TestSpecifiers_Prefer(t *testing.T) {
+	vs, err := ParseList([]string{"1.0+local", "0.9", "1.0", "1.5", "2.0rc1", "1.9", "1.5.0"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec   string
+		want   string
+		wantOk bool
+	}{
+		{"==1.0", "1.0", true},
+		{"==1.0.0", "1.0", true},
+		{"==1.0+local", "1.0+local", true},
+		{">=1.0, <2.0", "1.9", true},
+		{"~=1.4", "1.9", true},
+		{"==1.5.*", "1.5", true},
+		{"<1.0 || ==1.5", "1.5", true},
+		{">=3.0", "", false},
+		{"==1.2", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			got, ok := c.Prefer(vs)
+			assert.Equal(t, tt.wantOk, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+
+	c, err := NewSpecifiers(">=1.0")
+	require.NoError(t, err)
+	_, ok := c.Prefer(nil)
+	assert.False(t, ok)
+}
+
+func TestSpecifiers_SelectBest(t *testing.T) {
+	tests := []struct {
+		spec     string
+		versions []string
+		opts     []SpecifierOption
+		want     string
+		wantOk   bool
+	}{
+		{
+			spec:     ">=1.0",
+			versions: []string{"1.0", "1.1", "1.2rc1", "1.2.dev1"},
+			want:     "1.1",
+			wantOk:   true,
+		},
+		{
+			spec:     ">=1.0",
+			versions: []string{"0.9", "1.2a1", "1.2rc1", "1.2.dev1"},
+			want:     "1.2rc1",
+			wantOk:   true,
+		},
+		{
+			spec:     ">=1.0rc1",
+			versions: []string{"1.0rc1", "1.0rc2"},
+			want:     "1.0rc2",
+			wantOk:   true,
+		},
+		{
+			spec:     ">=1.0rc1",
+			versions: []string{"1.0", "1.1rc1", "2.0a1"},
+			want:     "2.0a1",
+			wantOk:   true,
+		},
+		{
+			spec:     "<2.0rc1",
+			versions: []string{"1.0", "1.1rc1"},
+			want:     "1.0",
+			wantOk:   true,
+		},
+		{
+			spec:     ">=1.0",
+			versions: []string{"1.0", "1.1", "1.2rc1"},
+			opts:     []SpecifierOption{WithPreRelease(true)},
+			want:     "1.2rc1",
+			wantOk:   true,
+		},
+		{
+			spec:     ">=2.0",
+			versions: []string{"1.0", "1.1", "1.2rc1"},
+		},
+		{
+			spec: ">=1.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.spec, tt.versions), func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec, tt.opts...)
+			require.NoError(t, err)
+
+			vs, err := ParseList(tt.versions)
+			require.NoError(t, err)
+
+			got, ok := c.SelectBest(vs)
+			assert.Equal(t, tt.wantOk, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestSpecifiers_Negate(t *testing.T) {
+	sample, err := ParseList([]string{
+		"0.9", "1.0.dev1", "1.0a1", "1.0", "1.0+local", "1.0.post1", "1.0.post2.dev1", "1.2", "1.4", "1.4.5",
+		"1.4.5+local", "1.5rc1", "1.5", "1.5.post1", "1.9", "2.0.dev1", "2.0rc1", "2.0", "2.0+local",
+		"2.0.post1", "2.0.post1.dev1", "2.0.post2", "2.5", "3.0", "1!1.0",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"==1.4", "!=1.4"},
+		{"!=1.4.*", "==1.4.*"},
+		{">=1.0", "<=1.0,!=1.0"},
+		{"<=2.0", ">=2.0,!=2.0"},
+		{"~=1.4.5", "<=1.4.5,!=1.4.5||!=1.4.*"},
+		{">1.0", "not >1.0"},
+		{"<2.0.post1", "not <2.0.post1"},
+		{"===1.0", "not ===1.0"},
+		{">=1.0,<2.0", "<=1.0,!=1.0||not <2.0"},
+		{">=1.0,<2.0 || ==3.0", "<=1.0,!=1.0,!=3.0||not <2.0,!=3.0"},
+		{"*", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := NewSpecifiers(tt.spec)
+			require.NoError(t, err)
+
+			got := c.Negate()
+			assert.Equal(t, tt.want, got.String())
+			for _, v := range sample {
+				assert.Equal(t, !c.Check(v), got.Check(v), v.String())
+			}
+
+			// The negated specifiers round trip unless they have no PEP 440 operator.
+			b, err := yaml.Marshal(got)
+			if strings.Contains(tt.want, "not ") {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				return
+			}
+			var decoded Specifiers
+			require.NoError(t, yaml.Unmarshal(b, &decoded))
+			assert.Equal(t, tt.want, decoded.String())
+			for _, v := range sample {
+				assert.Equal(t, got.Check(v), decoded.Check(v), v.String())
+			}
+		})
+	}
+
+	t.Run("zero value", func(t *testing.T) {
+		got := Specifiers{}.Negate()
+		assert.Equal(t, "*", got.String())
+		assert.True(t, got.Check(MustParse("1.0")))
+	})
+}
+
+func BenchmarkSpecifiers_Check(b *testing.B) {
+	c, err := NewSpecifiers(">= 1.0, != 1.3.*, < 2.0 || ~= 3.1.2 || == 4.0")
+	require.NoError(b, err)
+	v := MustParse("3.1.5")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Check(v)
+	}
+}
+
+func BenchmarkSpecifiers_CheckList(b *testing.B) {
+	// Versions as found in a package index, from 0.1 to 4.9.9 with pre-releases and post-releases.
+	var vs []Version
+	for major := 0; major < 5; major++ {
+		for minor := 0; minor < 10; minor++ {
+			for patch := 0; patch < 10; patch++ {
+				base := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+				for _, suffix := range []string{"", "rc1", ".post1", ".dev1"} {
+					vs = append(vs, MustParse(base+suffix))
+				}
+			}
+		}
+	}
+
+	specs := []string{
+		"~=2.4.1, <2.4.5",
+		">=1.0, !=1.3.*, <2.0",
+		"==3.1.*, >3.1.2",
+		"~=1.4 || ~=2.2, !=2.2.5",
+	}
+	for _, spec := range specs {
+		c, err := NewSpecifiers(spec)
+		require.NoError(b, err)
+
+		b.Run(spec, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, v := range vs {
+					c.Check(v)
+				}
+			}
+		})
+	}
+}
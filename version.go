@@ -3,8 +3,12 @@ package version
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/xerrors"
 
@@ -15,6 +19,9 @@ var (
 	// The compiled regular expression used to test the validity of a version.
 	versionRegex *regexp.Regexp
 
+	// The compiled regular expression used to find the longest version at the start of a string.
+	versionPrefixRegex *regexp.Regexp
+
 	// https://github.com/pypa/packaging/blob/a6407e3a7e19bd979e93f58cfc7f6641a7378c46/packaging/version.py#L459-L464
 	preReleaseAliases = map[string]string{
 		"a":       "a",
@@ -33,6 +40,16 @@ var (
 		"rev":  "post",
 		"r":    "post",
 	}
+
+	// Prefixes stripped by Coerce. Longer prefixes must come first.
+	coercePrefixes = []string{
+		"release-",
+		"release_",
+		"release",
+		"version-",
+		"version_",
+		"version",
+	}
 )
 
 const (
@@ -58,6 +75,8 @@ type Version struct {
 	key                key
 	preReleaseIncluded bool
 	original           string
+	// meta is the metadata attached by WithMeta, which is never modified in place.
+	meta map[string]interface{}
 }
 
 type key struct {
@@ -75,6 +94,25 @@ func (k key) compare(o key) int {
 	return p1.Compare(p2)
 }
 
+// Components is a structured decomposition of a version.
+type Components struct {
+	Epoch   int
+	Release []int
+
+	HasPre    bool
+	PreLabel  string
+	PreNumber int
+
+	HasPost    bool
+	PostNumber int
+
+	HasDev    bool
+	DevNumber int
+
+	HasLocal bool
+	Local    []string
+}
+
 type letterNumber struct {
 	letter part.String
 	number part.BigInt
@@ -86,6 +124,30 @@ func (ln letterNumber) isNull() bool {
 
 func init() {
 	versionRegex = regexp.MustCompile(`(?i)^\s*` + regex + `\s*$`)
+
+	versionPrefixRegex = regexp.MustCompile(`(?i)^\s*` + regex)
+	versionPrefixRegex.Longest()
+}
+
+// ValidVersion tests if the given string is a valid version without parsing it into a Version.
+func ValidVersion(v string) bool {
+	return isASCII(v) && versionRegex.MatchString(v)
+}
+
+// RoundTrips tests if the given version is equal to itself after being normalized by String
+// and parsed again, and if the result is normalized to the same string,
+// i.e. if storing the normalized form loses nothing.
+// It returns false if the version cannot be parsed.
+func RoundTrips(s string) bool {
+	v, err := Parse(s)
+	if err != nil {
+		return false
+	}
+	normalized, err := Parse(v.String())
+	if err != nil {
+		return false
+	}
+	return v.Equal(normalized) && normalized.String() == v.String()
 }
 
 // MustParse is like Parse but panics if the version cannot be parsed.
@@ -98,12 +160,55 @@ func MustParse(v string) Version {
 }
 
 // Parse parses the given version and returns a new Version.
+// Letters are case-insensitive and normalized to lowercase, e.g. "1.0RC1+ABC" is "1.0rc1+abc".
+// Only ASCII letters are accepted, so the result doesn't depend on Unicode case folding,
+// e.g. "1.0.poſt1" with the long s is malformed.
+// The pre-release spellings "c", "rc", "pre" and "preview" are all normalized to "rc",
+// so "1.0c1", "1.0rc1", "1.0pre1" and "1.0preview1" give identical versions that String as "1.0rc1".
+// Likewise, "alpha" is normalized to "a", "beta" to "b", and "rev" and "r" to "post".
 func Parse(v string) (Version, error) {
-	matches := versionRegex.FindStringSubmatch(v)
+	matches := matchVersion(v)
 	if matches == nil {
 		return Version{}, xerrors.Errorf("malformed version: %s", v)
 	}
+	return parseMatches(v, matches)
+}
+
+// ParseDetailed is like Parse but also returns the raw text matched by each named group of the version regex
+// before normalization, e.g. "v1.0-Alpha1" gives "release": "1.0", "pre": "-Alpha1", "pre_l": "Alpha" and "pre_n": "1".
+// The groups are "epoch", "release", "pre", "pre_l", "pre_n", "post", "post_n1", "post_l", "post_n2",
+// "dev", "dev_l", "dev_n" and "local", and only the groups that matched are included.
+// It is intended for debugging and explaining how a version is parsed.
+func ParseDetailed(v string) (Version, map[string]string, error) {
+	matches := matchVersion(v)
+	if matches == nil {
+		return Version{}, nil, xerrors.Errorf("malformed version: %s", v)
+	}
+
+	groups := map[string]string{}
+	for i, name := range versionRegex.SubexpNames() {
+		if name != "" && matches[i] != "" {
+			groups[name] = matches[i]
+		}
+	}
 
+	ver, err := parseMatches(v, matches)
+	if err != nil {
+		return Version{}, nil, err
+	}
+	return ver, groups, nil
+}
+
+// matchVersion returns the submatches of the version regex, or nil if the version is malformed.
+func matchVersion(v string) []string {
+	if !isASCII(v) {
+		return nil
+	}
+	return versionRegex.FindStringSubmatch(v)
+}
+
+// parseMatches builds a version from the submatches of the version regex.
+func parseMatches(v string, matches []string) (Version, error) {
 	var epoch, preN, postN, devN part.BigInt
 	var preL, postL, devL part.String
 	var release []part.BigInt
@@ -177,6 +282,127 @@ func Parse(v string) (Version, error) {
 	}, nil
 }
 
+// ParseStrict is like Parse but returns an error if the local version segment contains uppercase letters,
+// which Parse silently lowercases, e.g. "1.0+ABC" is parsed as "1.0+abc" by Parse but rejected by ParseStrict.
+// It is intended for validating metadata that must already be in the normalized form.
+func ParseStrict(v string) (Version, error) {
+	ver, groups, err := ParseDetailed(v)
+	if err != nil {
+		return Version{}, err
+	}
+
+	if local := groups["local"]; local != strings.ToLower(local) {
+		return Version{}, xerrors.Errorf("local version must be lowercase: %s", v)
+	}
+	return ver, nil
+}
+
+// ParsePrefix parses the longest valid version at the start of the given string
+// and returns it along with the rest of the string,
+// e.g. "1.2.3-linux.tar.gz" returns "1.2.3" and "-linux.tar.gz".
+func ParsePrefix(s string) (Version, string, error) {
+	m := versionPrefixRegex.FindString(s)
+	if m == "" {
+		return Version{}, "", xerrors.Errorf("no version found at the start: %s", s)
+	}
+
+	v, err := Parse(m)
+	if err != nil {
+		return Version{}, "", err
+	}
+	return v, s[len(m):], nil
+}
+
+// newVersion builds a version from its segments, e.g. when deriving a new version from an existing one.
+func newVersion(epoch part.BigInt, release []part.BigInt, pre, post, dev letterNumber, local string) Version {
+	v := Version{
+		epoch:   epoch,
+		release: release,
+		pre:     pre,
+		post:    post,
+		dev:     dev,
+		local:   local,
+		key:     cmpkey(epoch, release, pre, post, dev, local),
+	}
+	v.original = v.String()
+	return v
+}
+
+// ParseList parses the given versions and returns them in the same order.
+// It stops at the first version that cannot be parsed and returns its error.
+// The result is not sorted; use sort.Sort(SortedVersions(vs)) if needed.
+func ParseList(versions []string) ([]Version, error) {
+	vs := make([]Version, 0, len(versions))
+	for _, v := range versions {
+		ver, err := Parse(v)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to parse version list: %w", err)
+		}
+		vs = append(vs, ver)
+	}
+	return vs, nil
+}
+
+// ParseSorted is like ParseList but returns the versions sorted in ascending order.
+// It returns nil and the error if any version cannot be parsed.
+func ParseSorted(versions []string) (SortedVersions, error) {
+	vs, err := ParseList(versions)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(SortedVersions(vs))
+	return vs, nil
+}
+
+// ParseListIgnoringErrors is like ParseList but does not stop at the first
+// malformed version. It returns the versions that could be parsed along with
+// an error for each one that could not. The result is not sorted; use
+// sort.Sort(SortedVersions(vs)) if needed.
+func ParseListIgnoringErrors(versions []string) ([]Version, []error) {
+	var vs []Version
+	var errs []error
+	for _, v := range versions {
+		ver, err := Parse(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		vs = append(vs, ver)
+	}
+	return vs, errs
+}
+
+// Coerce is a lenient version of Parse for almost PEP 440 compliant versions such as Git tags.
+// If the version can't be parsed as-is, it applies the following transformations:
+//   - Leading and trailing whitespace is trimmed
+//   - A known prefix such as "release-" or "version-" is stripped
+//   - Trailing characters are dropped until the rest is a valid version,
+//     e.g. "1.2.3-foo+bar baz" becomes "1.2.3"
+//
+// It returns an error only if no version can be extracted.
+// Note that Parse already accepts forms like "v1.2.3-beta", which is normalized to "1.2.3b0".
+func Coerce(s string) (Version, error) {
+	if v, err := Parse(s); err == nil {
+		return v, nil
+	}
+
+	trimmed := strings.TrimSpace(s)
+	for _, prefix := range coercePrefixes {
+		if strings.HasPrefix(strings.ToLower(trimmed), prefix) {
+			trimmed = trimmed[len(prefix):]
+			break
+		}
+	}
+
+	for i := len(trimmed); i > 0; i-- {
+		if v, err := Parse(trimmed[:i]); err == nil {
+			v.original = s
+			return v, nil
+		}
+	}
+	return Version{}, xerrors.Errorf("unable to coerce version: %s", s)
+}
+
 // ref. https://github.com/pypa/packaging/blob/a6407e3a7e19bd979e93f58cfc7f6641a7378c46/packaging/version.py#L495
 func cmpkey(epoch part.BigInt, release []part.BigInt, pre, post, dev letterNumber, local string) key {
 	// Set default values
@@ -213,9 +439,10 @@ func cmpkey(epoch part.BigInt, release []part.BigInt, pre, post, dev letterNumbe
 	//   - Alpha numeric segments sort lexicographically
 	//   - Numeric segments sort numerically
 	//   - Shorter versions sort before longer versions when the prefixes match exactly
+	// The segments are separated by ".", "-" or "_", which are equivalent.
 	if local != "" {
 		var parts part.Parts
-		for _, l := range strings.Split(local, ".") {
+		for _, l := range splitLocal(local) {
 			if p, err := part.NewBigInt(l); err == nil {
 				parts = append(parts, p)
 			} else {
@@ -231,12 +458,24 @@ func cmpkey(epoch part.BigInt, release []part.BigInt, pre, post, dev letterNumbe
 // Compare compares this version to another version. This
 // returns -1, 0, or 1 if this version is smaller, equal,
 // or larger than the other version, respectively.
+//
+// Alternative spellings of pre-release and post-release segments
+// (e.g. "alpha" and "a", "rev" and "post") are normalized while parsing,
+// so they compare as equal. The zero value of Version is less than any parsed version.
 func (v Version) Compare(other Version) int {
-	// A quick, efficient equality check
-	if v.String() == other.String() {
+	// The zero value has no key and is less than any other version.
+	if v.IsZero() || other.IsZero() {
+		switch {
+		case !v.IsZero():
+			return 1
+		case !other.IsZero():
+			return -1
+		}
 		return 0
 	}
 
+	// The keys are compared directly, since formatting both versions for a quick equality check
+	// allocates on every comparison and the keys of equal versions compare as equal anyway.
 	k1 := v.key
 	k2 := other.key
 
@@ -246,6 +485,70 @@ func (v Version) Compare(other Version) int {
 	return k1.compare(k2)
 }
 
+// ComparePublic is like Compare but ignores the local segments,
+// e.g. "1.0+build1" and "1.0+build2" are equal. The zero value of Version is less than any parsed version.
+func (v Version) ComparePublic(other Version) int {
+	if v.IsZero() || other.IsZero() {
+		return v.Compare(other)
+	}
+
+	k1 := v.key
+	k2 := other.key
+
+	k1.local = part.NegativeInfinity
+	k2.local = part.NegativeInfinity
+
+	return k1.compare(k2)
+}
+
+// LocalOnlyDiffers tests if this version and another version have the same public version
+// but different local segments, e.g. "1.0+build1" and "1.0.0+build2", or "1.0+build1" and "1.0".
+// The local segments are compared as in Compare, so "1.0+ubuntu-1" and "1.0+ubuntu.1" don't differ.
+func (v Version) LocalOnlyDiffers(o Version) bool {
+	if v.IsZero() || o.IsZero() {
+		return false
+	}
+	return v.ComparePublic(o) == 0 && v.Compare(o) != 0
+}
+
+// Compare compares the versions a and b as in a.Compare(b).
+// It has the comparator signature expected by slices.SortFunc and similar functions,
+// e.g. slices.SortFunc(vs, version.Compare).
+func Compare(a, b Version) int {
+	return a.Compare(b)
+}
+
+// CompareStrings parses the given versions and compares them.
+// See Version.Compare for the result.
+func CompareStrings(a, b string) (int, error) {
+	v1, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	v2, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return v1.Compare(v2), nil
+}
+
+// LessStrings reports whether the version a is less than the version b.
+// It is intended to be used with sort.Slice. A malformed version is treated
+// as less than any valid version, and malformed versions are ordered lexically.
+func LessStrings(a, b string) bool {
+	v1, err1 := Parse(a)
+	v2, err2 := Parse(b)
+	switch {
+	case err1 != nil && err2 != nil:
+		return a < b
+	case err1 != nil:
+		return true
+	case err2 != nil:
+		return false
+	}
+	return v1.LessThan(v2)
+}
+
 // Equal tests if two versions are equal.
 func (v Version) Equal(o Version) bool {
 	return v.Compare(o) == 0
@@ -271,9 +574,87 @@ func (v Version) LessThanOrEqual(o Version) bool {
 	return v.Compare(o) <= 0
 }
 
+// Before is an alias of LessThan, named after time.Time.Before.
+func (v Version) Before(o Version) bool {
+	return v.LessThan(o)
+}
+
+// After is an alias of GreaterThan, named after time.Time.After.
+func (v Version) After(o Version) bool {
+	return v.GreaterThan(o)
+}
+
+// BetweenInclusive tests if this version is greater than or equal to lo and less than or equal to hi.
+// It is always false if lo is greater than hi.
+func (v Version) BetweenInclusive(lo, hi Version) bool {
+	return v.GreaterThanOrEqual(lo) && v.LessThanOrEqual(hi)
+}
+
+// EqualRelease tests if this version has the same epoch and release segment as another version,
+// ignoring the pre-release, post-release, development release and local segments.
+// Trailing zeros are ignored as in Equal, e.g. "1.0rc1", "1.0.0" and "1.0.0.0+local" are all the same release.
+func (v Version) EqualRelease(o Version) bool {
+	p1 := part.Parts{v.key.epoch, v.key.release}
+	p2 := part.Parts{o.key.epoch, o.key.release}
+	return p1.Compare(p2) == 0
+}
+
+// SameReleaseIgnoringEpoch is like EqualRelease but ignores the epoch as well,
+// e.g. "1!2.0" and "2.0.0rc1" have the same release. Combined with EqualRelease,
+// it detects versions that differ only by the epoch.
+func (v Version) SameReleaseIgnoringEpoch(o Version) bool {
+	return v.key.release.Compare(o.key.release) == 0
+}
+
+// EqualString is like Equal but parses the other version first.
+// It returns an error if the other version cannot be parsed.
+func (v Version) EqualString(o string) (bool, error) {
+	ov, err := Parse(o)
+	if err != nil {
+		return false, err
+	}
+	return v.Equal(ov), nil
+}
+
+// GreaterThanString is like GreaterThan but parses the other version first.
+// It returns an error if the other version cannot be parsed.
+func (v Version) GreaterThanString(o string) (bool, error) {
+	ov, err := Parse(o)
+	if err != nil {
+		return false, err
+	}
+	return v.GreaterThan(ov), nil
+}
+
+// LessThanString is like LessThan but parses the other version first.
+// It returns an error if the other version cannot be parsed.
+func (v Version) LessThanString(o string) (bool, error) {
+	ov, err := Parse(o)
+	if err != nil {
+		return false, err
+	}
+	return v.LessThan(ov), nil
+}
+
+// IsCompatibleWith tests if this version is a compatible release of the base version,
+// the same as the "~=" operator, e.g. "2.5" is compatible with "2.2" but "3.0" isn't.
+// It returns false if the base has fewer than two release segments or a local segment,
+// since they are not allowed with "~=".
+func (v Version) IsCompatibleWith(base Version) bool {
+	if len(base.release) < 2 || base.local != "" {
+		return false
+	}
+	return specifierCompatible(v, specifier{version: base.String(), parsed: base})
+}
+
 // String returns the full version string included pre-release
 // and metadata information.
+// The zero value of Version is returned as an empty string.
 func (v Version) String() string {
+	if len(v.release) == 0 {
+		return ""
+	}
+
 	var buf bytes.Buffer
 
 	// Epoch
@@ -310,8 +691,114 @@ func (v Version) String() string {
 	return buf.String()
 }
 
+// StringCompact is like String but drops trailing zeros of the release segment for display,
+// keeping at least minSegments numbers, e.g. "1.2.0.0" becomes "1.2" with 2 and "1.0.0rc1" becomes "1rc1" with 1.
+// A release segment can't be empty, so minSegments less than 1 is treated as 1.
+// The version itself is not modified.
+func (v Version) StringCompact(minSegments int) string {
+	if minSegments < 1 {
+		minSegments = 1
+	}
+
+	n := len(v.release)
+	for n > minSegments && v.release[n-1].IsNull() {
+		n--
+	}
+	v.release = v.release[:n]
+	return v.String()
+}
+
+// Format returns the version formatted according to the layout.
+// The following verbs are replaced with the segments in the normalized form,
+// and absent segments are replaced with an empty string:
+//   - %e: the epoch followed by "!", e.g. "1!", which is empty for the epoch 0
+//   - %r: the release segment, e.g. "1.2.3"
+//   - %p: the pre-release segment, e.g. "rc1"
+//   - %P: the post-release segment, e.g. ".post2"
+//   - %d: the development release segment, e.g. ".dev3"
+//   - %l: the local version segment, e.g. "+ubuntu.1"
+//   - %%: a literal "%"
+//
+// Any other character is copied as-is, so "%e%r%p%P%d%l" gives the same result as String
+// and "%r%p" gives "1.2.3rc1" for "1.2.3rc1.post2.dev3+ubuntu.1".
+func (v Version) Format(layout string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' || i == len(layout)-1 {
+			buf.WriteByte(layout[i])
+			continue
+		}
+
+		i++
+		switch layout[i] {
+		case 'e':
+			if v.epoch.Compare(part.Zero) == 1 {
+				fmt.Fprintf(&buf, "%s!", v.epoch)
+			}
+		case 'r':
+			for j, r := range v.release {
+				if j > 0 {
+					buf.WriteByte('.')
+				}
+				fmt.Fprintf(&buf, "%s", r)
+			}
+		case 'p':
+			if !v.pre.isNull() {
+				fmt.Fprintf(&buf, "%s%s", v.pre.letter, v.pre.number)
+			}
+		case 'P':
+			if !v.post.isNull() {
+				fmt.Fprintf(&buf, ".post%s", v.post.number)
+			}
+		case 'd':
+			if !v.dev.isNull() {
+				fmt.Fprintf(&buf, ".dev%s", v.dev.number)
+			}
+		case 'l':
+			if v.local != "" {
+				fmt.Fprintf(&buf, "+%s", v.local)
+			}
+		case '%':
+			buf.WriteByte('%')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(layout[i])
+		}
+	}
+	return buf.String()
+}
+
+// CanonicalKey returns a string that can be used as a map key.
+// Versions that are equal have the same key, e.g. "1.0alpha1", "1.0a1" and "1.0.0a1"
+// are all "1a1". Trailing zeros in the release segment are dropped and numbers and separators
+// in the local version are normalized, e.g. "1.0+ubuntu-01" is "1+ubuntu.1".
+func (v Version) CanonicalKey() string {
+	release := v.release
+	for len(release) > 1 && release[len(release)-1].IsNull() {
+		release = release[:len(release)-1]
+	}
+	v.release = release
+
+	if v.local != "" {
+		var local []string
+		for _, l := range splitLocal(v.local) {
+			if p, err := part.NewBigInt(l); err == nil {
+				l = p.String()
+			}
+			local = append(local, l)
+		}
+		v.local = strings.Join(local, ".")
+	}
+	return v.String()
+}
+
 // BaseVersion returns the base version
+// The zero value of Version is returned as an empty string.
 func (v Version) BaseVersion() string {
+	if len(v.release) == 0 {
+		return ""
+	}
+
 	var buf bytes.Buffer
 
 	// Epoch
@@ -328,23 +815,381 @@ func (v Version) BaseVersion() string {
 	return buf.String()
 }
 
+// ReleaseString returns only the release segment joined with dots, without the epoch,
+// e.g. "1!2.3.4rc1+local" returns "2.3.4".
+// The zero value of Version is returned as an empty string.
+func (v Version) ReleaseString() string {
+	return v.Format("%r")
+}
+
 // Original returns the original parsed version as-is, including any
 // potential whitespace, `v` prefix, etc.
 func (v Version) Original() string {
 	return v.original
 }
 
+// IsZero tests if the version is the zero value, such as the one returned with a parse error.
+func (v Version) IsZero() bool {
+	return len(v.release) == 0
+}
+
+// Clone returns a deep copy of the version.
+// Assigning a Version copies it shallowly, so the copy shares the release segment with the original.
+func (v Version) Clone() Version {
+	c := v
+	c.release = make([]part.BigInt, len(v.release))
+	copy(c.release, v.release)
+	c.key = cmpkey(c.epoch, c.release, c.pre, c.post, c.dev, c.local)
+	if v.meta != nil {
+		c.meta = make(map[string]interface{}, len(v.meta))
+		for k, m := range v.meta {
+			c.meta[k] = m
+		}
+	}
+	return c
+}
+
+// WithMeta returns a copy of the version with the given metadata attached, e.g. the yanked status
+// or the upload time. The metadata doesn't affect comparison, String and CanonicalKey,
+// so "1.0" with and without metadata are equal. The original version is not modified.
+// Clone keeps the metadata, but methods building a new version from the segments,
+// such as Increment or WithPost, don't.
+func (v Version) WithMeta(key string, value interface{}) Version {
+	meta := make(map[string]interface{}, len(v.meta)+1)
+	for k, m := range v.meta {
+		meta[k] = m
+	}
+	meta[key] = value
+	v.meta = meta
+	return v
+}
+
+// Meta returns the metadata attached with WithMeta for the given key.
+// It returns false if no metadata is attached for the key.
+func (v Version) Meta(key string) (interface{}, bool) {
+	m, ok := v.meta[key]
+	return m, ok
+}
+
+// Components returns all the segments of the version at once.
+// The local version is split on ".", "-" and "_".
+// Numbers that don't fit into int are clamped to the maximum value; use ReleaseBig for large release numbers.
+func (v Version) Components() Components {
+	c := Components{
+		Epoch:      bigIntToInt(v.epoch),
+		HasPre:     !v.pre.isNull(),
+		PreLabel:   string(v.pre.letter),
+		PreNumber:  bigIntToInt(v.pre.number),
+		HasPost:    !v.post.isNull(),
+		PostNumber: bigIntToInt(v.post.number),
+		HasDev:     !v.dev.isNull(),
+		DevNumber:  bigIntToInt(v.dev.number),
+		HasLocal:   v.local != "",
+		Local:      splitLocal(v.local),
+	}
+	for _, r := range v.release {
+		c.Release = append(c.Release, bigIntToInt(r))
+	}
+	return c
+}
+
+// AsMap returns the segments of the version as a map for templates and JSON encoding
+// with the following keys:
+//   - "epoch": the epoch as int
+//   - "release": the release segment as []int
+//   - "pre": the pre-release segment as string, e.g. "rc1", or "" if absent
+//   - "post": the post-release number as int, or nil if absent
+//   - "dev": the development release number as int, or nil if absent
+//   - "local": the local version as string, or "" if absent
+//   - "is_prerelease": the result of IsPreRelease
+//
+// Numbers are clamped as in Components.
+func (v Version) AsMap() map[string]interface{} {
+	c := v.Components()
+	m := map[string]interface{}{
+		"epoch":         c.Epoch,
+		"release":       c.Release,
+		"pre":           v.Format("%p"),
+		"post":          nil,
+		"dev":           nil,
+		"local":         v.Local(),
+		"is_prerelease": v.IsPreRelease(),
+	}
+	if c.HasPost {
+		m["post"] = c.PostNumber
+	}
+	if c.HasDev {
+		m["dev"] = c.DevNumber
+	}
+	return m
+}
+
+// ReleaseSegment returns the i-th number of the release segment.
+// It returns false if i is out of range, e.g. "1.2.3" returns (1, true) for 0 and (0, false) for 5.
+// A number that doesn't fit into int is clamped to the maximum value as in Components.
+func (v Version) ReleaseSegment(i int) (int, bool) {
+	if i < 0 || i >= len(v.release) {
+		return 0, false
+	}
+	return bigIntToInt(v.release[i]), true
+}
+
+// ReleaseBig returns the numbers of the release segment without overflow,
+// e.g. for date-based versions like "20231231235959.1". Use it instead of Components
+// and ReleaseSegment, which clamp numbers that don't fit into int.
+func (v Version) ReleaseBig() []*big.Int {
+	release := make([]*big.Int, 0, len(v.release))
+	for _, r := range v.release {
+		n, _ := new(big.Int).SetString(r.String(), 10)
+		release = append(release, n)
+	}
+	return release
+}
+
+// PreReleaseLabel returns the normalized pre-release letter, which is one of "a", "b" or "rc".
+// e.g. "1.0alpha1" returns ("a", true) and "1.0preview2" returns ("rc", true).
+// It returns false if the version has no pre-release segment.
+func (v Version) PreReleaseLabel() (string, bool) {
+	if v.pre.isNull() {
+		return "", false
+	}
+	return string(v.pre.letter), true
+}
+
+// PreReleaseNumber returns the pre-release number, e.g. "1.0rc2" returns (2, true).
+// An implicit number is returned as 0, e.g. "1.0rc" returns (0, true).
+// It returns false if the version has no pre-release segment.
+func (v Version) PreReleaseNumber() (int, bool) {
+	if v.pre.isNull() {
+		return 0, false
+	}
+	return bigIntToInt(v.pre.number), true
+}
+
+// PostReleaseNumber returns the post-release number, e.g. "1.0.post2" returns (2, true).
+// The implicit form is the same as the explicit one, e.g. "1.0-1", "1.0rev1" and "1.0.post1" all return (1, true),
+// and an implicit number is returned as 0, e.g. "1.0.post" returns (0, true).
+// It returns false if the version has no post-release segment.
+func (v Version) PostReleaseNumber() (int, bool) {
+	if v.post.isNull() {
+		return 0, false
+	}
+	return bigIntToInt(v.post.number), true
+}
+
+// Truncate returns a new version keeping the epoch and only the first n release segments.
+// The release segment is padded with zeros if it has fewer than n segments, and
+// the pre-release, post-release, development release and local segments are dropped.
+// A release segment can't be empty, so n less than 1 is treated as 1.
+// e.g. "1.4.7.post2" truncated to 2 becomes "1.4"
+func (v Version) Truncate(n int) Version {
+	if n < 1 {
+		n = 1
+	}
+
+	release := make([]part.BigInt, n)
+	copy(release, v.release)
+
+	return newVersion(v.epoch, release, letterNumber{}, letterNumber{}, letterNumber{}, "")
+}
+
+// BumpPre returns a new version with the pre-release number incremented,
+// e.g. "1.0rc1" becomes "1.0rc2". The post-release, development release
+// and local segments are dropped. It returns an error if the version is not a pre-release.
+func (v Version) BumpPre() (Version, error) {
+	if v.pre.isNull() {
+		return Version{}, xerrors.Errorf("no pre-release segment: %s", v)
+	}
+	pre := letterNumber{
+		letter: v.pre.letter,
+		number: incrementBigInt(v.pre.number),
+	}
+	return newVersion(v.epoch, v.release, pre, letterNumber{}, letterNumber{}, ""), nil
+}
+
+// BumpPost returns a new version with the post-release number incremented,
+// e.g. "1.0.post1" becomes "1.0.post2". The development release and local segments
+// are dropped. It returns an error if the version is not a post-release.
+func (v Version) BumpPost() (Version, error) {
+	if v.post.isNull() {
+		return Version{}, xerrors.Errorf("no post-release segment: %s", v)
+	}
+	post := letterNumber{
+		letter: v.post.letter,
+		number: incrementBigInt(v.post.number),
+	}
+	return newVersion(v.epoch, v.release, v.pre, post, letterNumber{}, ""), nil
+}
+
+// BumpDev returns a new version with the development release number incremented,
+// e.g. "1.0.dev1" becomes "1.0.dev2". The local segment is dropped.
+// It returns an error if the version is not a development release.
+func (v Version) BumpDev() (Version, error) {
+	if v.dev.isNull() {
+		return Version{}, xerrors.Errorf("no development release segment: %s", v)
+	}
+	dev := letterNumber{
+		letter: v.dev.letter,
+		number: incrementBigInt(v.dev.number),
+	}
+	return newVersion(v.epoch, v.release, v.pre, v.post, dev, ""), nil
+}
+
+// Increment returns a new version with the i-th number of the release segment incremented
+// and the following numbers set to zero, e.g. "1.2.3" becomes "2.0.0" for 0 and "1.2.4" for 2.
+// The release segment is extended with zeros if i is out of range, e.g. "1.2" becomes "1.2.0.1" for 3.
+// The epoch is kept, while the pre-release, post-release, development release and local segments are dropped.
+// It returns an error if i is negative.
+func (v Version) Increment(i int) (Version, error) {
+	if i < 0 {
+		return Version{}, xerrors.Errorf("negative release segment index: %d", i)
+	}
+
+	n := len(v.release)
+	if i >= n {
+		n = i + 1
+	}
+
+	release := make([]part.BigInt, n)
+	for j := range release {
+		release[j] = intToBigInt(0)
+		if j < i && j < len(v.release) {
+			release[j] = v.release[j]
+		}
+	}
+	if i < len(v.release) {
+		release[i] = incrementBigInt(v.release[i])
+	} else {
+		release[i] = intToBigInt(1)
+	}
+	return newVersion(v.epoch, release, letterNumber{}, letterNumber{}, letterNumber{}, ""), nil
+}
+
+// WithEpoch returns a new version with the epoch replaced, e.g. "2.0" with epoch 1 becomes "1!2.0".
+// The other segments are kept as-is. It returns an error if the epoch is negative.
+func (v Version) WithEpoch(e int) (Version, error) {
+	if e < 0 {
+		return Version{}, xerrors.Errorf("negative epoch: %d", e)
+	}
+	return newVersion(intToBigInt(e), v.release, v.pre, v.post, v.dev, v.local), nil
+}
+
+// WithRelease returns a new version with the release segment replaced, e.g. "1!2.0rc1" with (3, 1) becomes "1!3.1".
+// The epoch is kept, while the pre-release, post-release, development release and local segments are dropped.
+// It returns an error if no number is given or any number is negative.
+func (v Version) WithRelease(segments ...int) (Version, error) {
+	if len(segments) == 0 {
+		return Version{}, xerrors.New("empty release segment")
+	}
+
+	release := make([]part.BigInt, 0, len(segments))
+	for _, s := range segments {
+		if s < 0 {
+			return Version{}, xerrors.Errorf("negative release number: %d", s)
+		}
+		release = append(release, intToBigInt(s))
+	}
+	return newVersion(v.epoch, release, letterNumber{}, letterNumber{}, letterNumber{}, ""), nil
+}
+
+// WithPre returns a new version with the pre-release segment replaced, e.g. "1.0" with ("rc", 1)
+// becomes "1.0rc1". The letter may be any alias such as "alpha" or "preview", and a negative n
+// removes the pre-release segment. The other segments are kept as-is.
+// It returns an error if the letter is not a pre-release letter.
+func (v Version) WithPre(letter string, n int) (Version, error) {
+	if n < 0 {
+		return newVersion(v.epoch, v.release, letterNumber{}, v.post, v.dev, v.local), nil
+	}
+
+	l, ok := preReleaseAliases[strings.ToLower(letter)]
+	if !ok {
+		return Version{}, xerrors.Errorf("unknown pre-release letter: %s", letter)
+	}
+	pre := letterNumber{
+		letter: part.String(l),
+		number: intToBigInt(n),
+	}
+	return newVersion(v.epoch, v.release, pre, v.post, v.dev, v.local), nil
+}
+
+// WithPost returns a new version with the post-release segment replaced, e.g. "1.0" with 2
+// becomes "1.0.post2". A negative n removes the post-release segment. The other segments are kept as-is.
+func (v Version) WithPost(n int) Version {
+	var post letterNumber
+	if n >= 0 {
+		post = letterNumber{
+			letter: "post",
+			number: intToBigInt(n),
+		}
+	}
+	return newVersion(v.epoch, v.release, v.pre, post, v.dev, v.local)
+}
+
+// WithDev returns a new version with the development release segment replaced, e.g. "1.0" with 3
+// becomes "1.0.dev3". A negative n removes the development release segment. The other segments are kept as-is.
+func (v Version) WithDev(n int) Version {
+	var dev letterNumber
+	if n >= 0 {
+		dev = letterNumber{
+			letter: "dev",
+			number: intToBigInt(n),
+		}
+	}
+	return newVersion(v.epoch, v.release, v.pre, v.post, dev, v.local)
+}
+
+// WithoutDev returns a new version without the development release segment, keeping the other segments,
+// e.g. "1.0rc1.dev3" becomes "1.0rc1". It is the same as WithDev(-1).
+func (v Version) WithoutDev() Version {
+	return v.WithDev(-1)
+}
+
+// ToStable returns the final release of the version by removing the pre-release, development release
+// and local segments, e.g. "1.2.0rc3", "1.2.0.dev5" and "1.2.0rc3.dev5+local" all become "1.2.0".
+// The post-release segment is kept since a post-release of a final release is stable,
+// e.g. "1.2.0.post1.dev2" and "1.2.0rc3.post1" both become "1.2.0.post1".
+func (v Version) ToStable() Version {
+	return newVersion(v.epoch, v.release, letterNumber{}, v.post, letterNumber{}, "")
+}
+
 // Local returns the local version
 func (v Version) Local() string {
 	return v.local
 }
 
+// LocalSegments returns the lowercased local version split into the segments used for comparison,
+// e.g. "1.0+Ubuntu-1.2" returns ["ubuntu" "1" "2"]. The separators ".", "-" and "_" are equivalent.
+// It returns nil if the version has no local segment.
+func (v Version) LocalSegments() []string {
+	return splitLocal(v.local)
+}
+
 // Public returns the public version
 func (v Version) Public() string {
 	return strings.SplitN(v.String(), "+", 2)[0]
 }
 
-// IsPreRelease returns if it is a pre-release
+// PublicVersion is like Public but returns a Version without parsing the string again,
+// e.g. "1.0+ubuntu.1" returns the version "1.0". As with the other builders,
+// Original of the result is the normalized string, e.g. "1.0" for "v1.0+ubuntu.1".
+func (v Version) PublicVersion() Version {
+	return newVersion(v.epoch, v.release, v.pre, v.post, v.dev, "")
+}
+
+// withoutLocal is like PublicVersion but only recomputes the key for comparisons,
+// which saves formatting the version on every specifier check. Original of the result is not updated.
+func (v Version) withoutLocal() Version {
+	if v.local == "" {
+		return v
+	}
+	v.local = ""
+	v.key = cmpkey(v.epoch, v.release, v.pre, v.post, v.dev, v.local)
+	return v
+}
+
+// IsPreRelease returns if it is a pre-release.
+// A version with a pre-release or development release segment is a pre-release
+// even if it also has a post-release segment, e.g. "1.0rc1.post2".
 func (v Version) IsPreRelease() bool {
 	if v.preReleaseIncluded {
 		return false
@@ -357,6 +1202,83 @@ func (v Version) IsPostRelease() bool {
 	return !v.post.isNull()
 }
 
+// IsFinal returns if it is a final release without pre-release, post-release,
+// development release and local segments, e.g. "1.0" and "1!2.0" but not "1.0+local".
+// Unlike ReleaseKind, a local segment makes the version non-final.
+func (v Version) IsFinal() bool {
+	return v.pre.isNull() && v.post.isNull() && v.dev.isNull() && v.local == ""
+}
+
+// ReleaseKind is a single classification of a version.
+type ReleaseKind int
+
+const (
+	// Final is a version without pre-release, post-release and development release segments, e.g. "1.0".
+	Final ReleaseKind = iota
+	// PreRelease is a version with a pre-release segment and without a development release segment, e.g. "1.0rc1".
+	PreRelease
+	// PostRelease is a version with only a post-release segment, e.g. "1.0.post1".
+	PostRelease
+	// DevRelease is a version with a development release segment, e.g. "1.0.dev1".
+	DevRelease
+)
+
+// String returns the name of the release kind.
+func (k ReleaseKind) String() string {
+	switch k {
+	case Final:
+		return "final"
+	case PreRelease:
+		return "pre-release"
+	case PostRelease:
+		return "post-release"
+	case DevRelease:
+		return "dev-release"
+	}
+	return fmt.Sprintf("ReleaseKind(%d)", int(k))
+}
+
+// ReleaseKind returns the kind of the release. When a version has multiple segments,
+// the precedence follows the sorting rules of PEP 440: a development release sorts
+// before its non-dev counterpart and a pre-release sorts before the final release even
+// with a post-release segment, so DevRelease > PreRelease > PostRelease > Final.
+// e.g. "1.0rc1.post1.dev2" is DevRelease and "1.0rc1.post2" is PreRelease.
+func (v Version) ReleaseKind() ReleaseKind {
+	switch {
+	case !v.dev.isNull():
+		return DevRelease
+	case !v.pre.isNull():
+		return PreRelease
+	case !v.post.isNull():
+		return PostRelease
+	}
+	return Final
+}
+
+// PreReleaseWeight returns the stability tier of the version for grouping and sorting:
+//   - 3 for a final release or a post-release, e.g. "1.0" and "1.0.post1"
+//   - 2 for a release candidate, e.g. "1.0rc1"
+//   - 1 for a beta, e.g. "1.0b1"
+//   - 0 for an alpha, e.g. "1.0a1"
+//   - -1 for a development release without a pre-release segment, e.g. "1.0.dev1" and "1.0.post1.dev1"
+//
+// The pre-release letter takes precedence over the development release segment, so "1.0rc1.dev1" is 2.
+func (v Version) PreReleaseWeight() int {
+	switch {
+	case !v.pre.isNull():
+		switch v.pre.letter {
+		case "a":
+			return 0
+		case "b":
+			return 1
+		}
+		return 2
+	case !v.dev.isNull():
+		return -1
+	}
+	return 3
+}
+
 type SortedVersions []Version
 
 func (s SortedVersions) Len() int {
@@ -371,3 +1293,56 @@ func (s SortedVersions) Less(i, j int) bool {
 func (s SortedVersions) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
+
+// GroupByRelease groups the versions by their first n release segments, preserving their order.
+// The key is the normalized truncated release segment, so that "1.0" and "1.00" are grouped together,
+// and a non-zero epoch is kept to separate the epochs, e.g. "2.1.3" and "1!2.1.3" are grouped
+// under "2.1" and "1!2.1" with n=2. Versions with fewer segments are padded with zeros as in Truncate.
+func GroupByRelease(vs []Version, n int) map[string][]Version {
+	groups := map[string][]Version{}
+	for _, v := range vs {
+		key := v.Truncate(n).String()
+		groups[key] = append(groups[key], v)
+	}
+	return groups
+}
+
+// bigIntToInt converts the given number to int.
+// Numbers that don't fit into int are clamped to the maximum value.
+func bigIntToInt(b part.BigInt) int {
+	n, _ := strconv.Atoi(b.String())
+	return n
+}
+
+// intToBigInt converts the given non-negative number to part.BigInt.
+func intToBigInt(n int) part.BigInt {
+	b, _ := part.NewBigInt(strconv.Itoa(n))
+	return b
+}
+
+func incrementBigInt(b part.BigInt) part.BigInt {
+	n, _ := new(big.Int).SetString(b.String(), 10)
+	incremented, _ := part.NewBigInt(n.Add(n, big.NewInt(1)).String())
+	return incremented
+}
+
+// isASCII tests if the string consists of ASCII characters only.
+// The case-insensitive version regex also matches some non-ASCII letters through
+// Unicode case folding, e.g. "ſ" (long s) for "s" and "K" (Kelvin sign) for "k".
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func splitLocal(local string) []string {
+	if local == "" {
+		return nil
+	}
+	return strings.FieldsFunc(local, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
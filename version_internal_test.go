@@ -0,0 +1,26 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/go-version/pkg/part"
+)
+
+func TestVersion_Clone(t *testing.T) {
+	v := MustParse("1!1.2.3rc1.post2.dev3+local")
+
+	c := v.Clone()
+	assert.True(t, c.Equal(v))
+	assert.Equal(t, v.String(), c.String())
+	assert.Equal(t, v.Original(), c.Original())
+
+	n, err := part.NewBigInt("9")
+	require.NoError(t, err)
+	c.release[0] = n
+
+	assert.Equal(t, "1!1.2.3rc1.post2.dev3+local", v.String())
+	assert.Equal(t, "1!9.2.3rc1.post2.dev3+local", c.String())
+}
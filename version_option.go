@@ -0,0 +1,83 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+var localRegexp = regexp.MustCompile(`^[A-Za-z0-9]+(?:[-_.][A-Za-z0-9]+)*$`)
+
+// Option sets an optional segment of a version built by New.
+type Option interface {
+	apply(Version) (Version, error)
+}
+
+type preOption struct {
+	letter string
+	n      int
+}
+
+// WithPreOpt sets the pre-release segment, e.g. ("rc", 1) for "rc1".
+// The letter may be any alias such as "alpha" or "preview".
+func WithPreOpt(letter string, n int) Option {
+	return preOption{letter: letter, n: n}
+}
+
+func (o preOption) apply(v Version) (Version, error) {
+	if o.n < 0 {
+		return Version{}, xerrors.Errorf("negative pre-release number: %d", o.n)
+	}
+	return v.WithPre(o.letter, o.n)
+}
+
+// WithPostOpt sets the post-release number.
+type WithPostOpt int
+
+func (o WithPostOpt) apply(v Version) (Version, error) {
+	if o < 0 {
+		return Version{}, xerrors.Errorf("negative post-release number: %d", o)
+	}
+	return v.WithPost(int(o)), nil
+}
+
+// WithDevOpt sets the development release number.
+type WithDevOpt int
+
+func (o WithDevOpt) apply(v Version) (Version, error) {
+	if o < 0 {
+		return Version{}, xerrors.Errorf("negative development release number: %d", o)
+	}
+	return v.WithDev(int(o)), nil
+}
+
+// WithLocalOpt sets the local version, e.g. "ubuntu.1". It is normalized to lowercase.
+type WithLocalOpt string
+
+func (o WithLocalOpt) apply(v Version) (Version, error) {
+	if !localRegexp.MatchString(string(o)) {
+		return Version{}, xerrors.Errorf("invalid local version: %s", o)
+	}
+	return newVersion(v.epoch, v.release, v.pre, v.post, v.dev, strings.ToLower(string(o))), nil
+}
+
+// New returns a new version built from the given numbers without parsing a string,
+// e.g. New(1, []int{2, 0}, WithPreOpt("rc", 1), WithLocalOpt("build1")) is "1!2.0rc1+build1".
+// It returns an error if the release segment is empty, a number is negative or a segment is invalid.
+func New(epoch int, release []int, opts ...Option) (Version, error) {
+	v, err := Version{}.WithEpoch(epoch)
+	if err != nil {
+		return Version{}, err
+	}
+	if v, err = v.WithRelease(release...); err != nil {
+		return Version{}, err
+	}
+
+	for _, o := range opts {
+		if v, err = o.apply(v); err != nil {
+			return Version{}, err
+		}
+	}
+	return v, nil
+}
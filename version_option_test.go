@@ -0,0 +1,70 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		epoch   int
+		release []int
+		opts    []Option
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "release only",
+			release: []int{1, 2, 3},
+			want:    "1.2.3",
+		},
+		{
+			name:    "every segment",
+			epoch:   1,
+			release: []int{2, 0},
+			opts:    []Option{WithPreOpt("rc", 1), WithPostOpt(2), WithDevOpt(3), WithLocalOpt("build1")},
+			want:    "1!2.0rc1.post2.dev3+build1",
+		},
+		{
+			name:    "aliases and case",
+			release: []int{1, 0},
+			opts:    []Option{WithPreOpt("preview", 0), WithLocalOpt("Ubuntu-1")},
+			want:    "1.0rc0+ubuntu-1",
+		},
+		{
+			name:    "zero numbers",
+			release: []int{0},
+			opts:    []Option{WithPostOpt(0), WithDevOpt(0)},
+			want:    "0.post0.dev0",
+		},
+		{name: "empty release", wantErr: "empty release segment"},
+		{name: "negative epoch", epoch: -1, release: []int{1}, wantErr: "negative epoch"},
+		{name: "negative release", release: []int{1, -1}, wantErr: "negative release number"},
+		{name: "negative pre", release: []int{1}, opts: []Option{WithPreOpt("a", -1)}, wantErr: "negative pre-release number"},
+		{name: "unknown pre", release: []int{1}, opts: []Option{WithPreOpt("x", 1)}, wantErr: "unknown pre-release letter"},
+		{name: "negative post", release: []int{1}, opts: []Option{WithPostOpt(-1)}, wantErr: "negative post-release number"},
+		{name: "negative dev", release: []int{1}, opts: []Option{WithDevOpt(-1)}, wantErr: "negative development release number"},
+		{name: "invalid local", release: []int{1}, opts: []Option{WithLocalOpt("a+b")}, wantErr: "invalid local version"},
+		{name: "empty local", release: []int{1}, opts: []Option{WithLocalOpt("")}, wantErr: "invalid local version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.epoch, tt.release, tt.opts...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+
+			parsed := MustParse(tt.want)
+			assert.True(t, got.Equal(parsed))
+			assert.Equal(t, parsed.CanonicalKey(), got.CanonicalKey())
+			assert.Equal(t, parsed.Components(), got.Components())
+		})
+	}
+}
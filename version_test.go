@@ -1,6 +1,8 @@
 package version_test
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,6 +102,111 @@ func TestParseInvalidVersion(t *testing.T) {
 	}
 }
 
+func TestValidVersion(t *testing.T) {
+	for _, v := range versions {
+		t.Run(v, func(t *testing.T) {
+			assert.True(t, version.ValidVersion(v))
+		})
+	}
+
+	for _, v := range []string{"", "french toast", "1.0+a+", "1.0++", "1.0+_foobar", "1.0.*"} {
+		t.Run(v, func(t *testing.T) {
+			assert.False(t, version.ValidVersion(v))
+		})
+	}
+}
+
+func TestRoundTrips(t *testing.T) {
+	// https://peps.python.org/pep-0440/#normalization
+	pep440 := []string{
+		"1.0", "1.1RC1", "1.0-1", "1.0.post", "1.0-r4", "1.0_rev_4", "1.0-dev", "1.0DEV3",
+		"1.0a", "1.0.ALPHA.2", "1.0-beta", "1.0preview1", "1.0pre1", "1.0c1", "v1.0", " 1.0\n",
+		"00!1.00", "1.0+Ubuntu-1", "1.0+ubuntu_1.2", "1.0+01", "1!2.0.0.0rc1.post2.dev3+abc.4",
+	}
+	for _, v := range append(pep440, versions...) {
+		t.Run(v, func(t *testing.T) {
+			assert.True(t, version.RoundTrips(v))
+		})
+	}
+
+	for _, v := range []string{"", "french toast", "1.0++"} {
+		t.Run(v, func(t *testing.T) {
+			assert.False(t, version.RoundTrips(v))
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "happy path",
+			versions: []string{"1.0", "0.9", "1.0a1"},
+			want:     []string{"1.0", "0.9", "1.0a1"},
+		},
+		{
+			name:     "empty",
+			versions: []string{},
+			want:     []string{},
+		},
+		{
+			name:     "malformed version",
+			versions: []string{"1.0", "french toast", "1.0++"},
+			wantErr:  "malformed version: french toast",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := version.ParseList(tt.versions)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			gotStrs := []string{}
+			for _, v := range got {
+				gotStrs = append(gotStrs, v.String())
+			}
+			assert.Equal(t, tt.want, gotStrs)
+		})
+	}
+}
+
+func TestParseSorted(t *testing.T) {
+	got, err := version.ParseSorted([]string{"1.0", "0.9", "1.0a1", "1.0.post1", "1!0.1"})
+	require.NoError(t, err)
+
+	var gotStrs []string
+	for _, v := range got {
+		gotStrs = append(gotStrs, v.String())
+	}
+	assert.Equal(t, []string{"0.9", "1.0a1", "1.0", "1.0.post1", "1!0.1"}, gotStrs)
+
+	got, err = version.ParseSorted([]string{"1.0", "french toast"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed version: french toast")
+	assert.Nil(t, got)
+}
+
+func TestParseListIgnoringErrors(t *testing.T) {
+	got, errs := version.ParseListIgnoringErrors([]string{"1.0", "french toast", "0.9", "1.0++"})
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), "french toast")
+	assert.Contains(t, errs[1].Error(), "1.0++")
+
+	var gotStrs []string
+	for _, v := range got {
+		gotStrs = append(gotStrs, v.String())
+	}
+	assert.Equal(t, []string{"1.0", "0.9"}, gotStrs)
+}
+
 func TestVersion_String(t *testing.T) {
 	tests := []struct {
 		version string
@@ -228,6 +335,143 @@ func TestVersion_String(t *testing.T) {
 	}
 }
 
+func TestVersion_Separators(t *testing.T) {
+	separators := []string{"", "-", "_", "."}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		// Pre-release
+		{"1.0%salpha%s1", "1.0a1"},
+		{"1.0%sa%s1", "1.0a1"},
+		{"1.0%sbeta%s2", "1.0b2"},
+		{"1.0%sc%s3", "1.0rc3"},
+		{"1.0%spre%s3", "1.0rc3"},
+		{"1.0%spreview%s3", "1.0rc3"},
+		// Post-release
+		{"1.0%spost%s4", "1.0.post4"},
+		{"1.0%srev%s4", "1.0.post4"},
+		{"1.0%sr%s4", "1.0.post4"},
+		// Development release
+		{"1.0%sdev%s5", "1.0.dev5"},
+		// Combined
+		{"1.0%src%s1.post2.dev3", "1.0rc1.post2.dev3"},
+		{"1.0rc1%spost%s2.dev3", "1.0rc1.post2.dev3"},
+		{"1.0rc1.post2%sdev%s3", "1.0rc1.post2.dev3"},
+	}
+	for _, tt := range tests {
+		for _, sep1 := range separators {
+			for _, sep2 := range separators {
+				v := fmt.Sprintf(tt.format, sep1, sep2)
+				t.Run(v, func(t *testing.T) {
+					got, err := version.Parse(v)
+					require.NoError(t, err)
+					assert.Equal(t, tt.want, got.String())
+					assert.True(t, got.Equal(version.MustParse(tt.want)))
+				})
+			}
+		}
+	}
+
+	// Implicit post-release and numbers
+	for _, tt := range [][2]string{
+		{"1.0-1", "1.0.post1"},
+		{"1.0-a-1", "1.0a1"},
+		{"1.0_alpha_1", "1.0a1"},
+		{"1.0.pre.1", "1.0rc1"},
+		{"1.0-a", "1.0a0"},
+		{"1.0_post", "1.0.post0"},
+		{"1.0.dev", "1.0.dev0"},
+		{"1.0a1-1", "1.0a1.post1"},
+		{"1.0a1-1-dev2", "1.0a1.post1.dev2"},
+	} {
+		t.Run(tt[0], func(t *testing.T) {
+			got, err := version.Parse(tt[0])
+			require.NoError(t, err)
+			assert.Equal(t, tt[1], got.String())
+		})
+	}
+}
+
+func TestVersion_StringCompact(t *testing.T) {
+	tests := []struct {
+		version     string
+		minSegments int
+		want        string
+	}{
+		{"1.2.0.0", 2, "1.2"},
+		{"1.2.0.0", 1, "1.2"},
+		{"1.2.0.0", 3, "1.2.0"},
+		{"1.2.0.0", 5, "1.2.0.0"},
+		{"1.0.0", 1, "1"},
+		{"1.0.0", 2, "1.0"},
+		{"1.0.0", 0, "1"},
+		{"0.0", 1, "0"},
+		{"1.0.1", 1, "1.0.1"},
+		{"1!1.0.0rc1.post2.dev3+local.0", 1, "1!1rc1.post2.dev3+local.0"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.version, tt.minSegments), func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.StringCompact(tt.minSegments))
+			assert.True(t, v.Equal(version.MustParse(tt.want)))
+			assert.Equal(t, version.MustParse(tt.version).String(), v.String())
+		})
+	}
+}
+
+func TestVersion_Format(t *testing.T) {
+	tests := []struct {
+		version string
+		layout  string
+		want    string
+	}{
+		{"1!1.2.3rc1.post2.dev3+ubuntu.1", "%e%r%p%P%d%l", "1!1.2.3rc1.post2.dev3+ubuntu.1"},
+		{"1!1.2.3rc1.post2.dev3+ubuntu.1", "%r%p", "1.2.3rc1"},
+		{"1!1.2.3rc1.post2.dev3+ubuntu.1", "%r", "1.2.3"},
+		{"1.2.3", "%e%r%p%P%d%l", "1.2.3"},
+		{"1.02alpha1", "v%r (%p)", "v1.2 (a1)"},
+		{"1.2", "%r%% %x%", "1.2% %x%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.Format(tt.layout))
+		})
+	}
+
+	for _, v := range versions {
+		t.Run(v, func(t *testing.T) {
+			ver, err := version.Parse(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, ver.String(), ver.Format("%e%r%p%P%d%l"))
+		})
+	}
+}
+
+func TestVersion_Zero(t *testing.T) {
+	var v version.Version
+	assert.True(t, v.IsZero())
+	assert.False(t, version.MustParse("0").IsZero())
+	assert.False(t, version.MustParse("0!0.0").IsZero())
+
+	v, err := version.Parse("french toast")
+	require.Error(t, err)
+	assert.True(t, v.IsZero())
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, "", v.String())
+		assert.Equal(t, "", v.BaseVersion())
+		assert.Equal(t, "", v.Public())
+		assert.Equal(t, "", v.CanonicalKey())
+	})
+}
+
 func TestVersion_LessThan_LessThanOrEqual(t *testing.T) {
 	var tests [][2]string
 	for i, v1 := range versions {
@@ -247,6 +491,41 @@ func TestVersion_LessThan_LessThanOrEqual(t *testing.T) {
 	}
 }
 
+func TestVersion_Before_After(t *testing.T) {
+	for i, s1 := range versions {
+		for _, s2 := range versions[i:] {
+			v1, v2 := parseVersions(t, s1, s2)
+			assert.Equal(t, v1.LessThan(v2), v1.Before(v2), "%s < %s", s1, s2)
+			assert.Equal(t, v2.LessThan(v1), v2.Before(v1), "%s < %s", s2, s1)
+			assert.Equal(t, v1.GreaterThan(v2), v1.After(v2), "%s > %s", s1, s2)
+			assert.Equal(t, v2.GreaterThan(v1), v2.After(v1), "%s > %s", s2, s1)
+		}
+	}
+}
+
+func TestVersion_BetweenInclusive(t *testing.T) {
+	tests := []struct {
+		version string
+		lo      string
+		hi      string
+		want    bool
+	}{
+		{"1.5", "1.0", "2.0", true},
+		{"1.0", "1.0", "2.0", true},
+		{"2.0.0", "1.0", "2.0", true},
+		{"2.0.post1", "1.0", "2.0", false},
+		{"1.0rc1", "1.0", "2.0", false},
+		{"1.0", "1.0", "1.0", true},
+		{"1.5", "2.0", "1.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s %s", tt.version, tt.lo, tt.hi), func(t *testing.T) {
+			v := version.MustParse(tt.version)
+			assert.Equal(t, tt.want, v.BetweenInclusive(version.MustParse(tt.lo), version.MustParse(tt.hi)))
+		})
+	}
+}
+
 func TestVersion_LessThanOrEqual(t *testing.T) {
 	var tests [][2]string
 	for i, v1 := range versions {
@@ -279,6 +558,335 @@ func TestVersion_Equal(t *testing.T) {
 	}
 }
 
+func TestVersion_CompareString(t *testing.T) {
+	tests := []struct {
+		version     string
+		other       string
+		wantEqual   bool
+		wantGreater bool
+		wantLess    bool
+		wantErr     bool
+	}{
+		{"1.0", "1.0.0", true, false, false, false},
+		{"1.0", "1.0alpha1", false, true, false, false},
+		{"1.0", "1!0.1", false, false, true, false},
+		{"1.0+abc", "1.0", false, true, false, false},
+		{"1.0", "french toast", false, false, false, true},
+		{"1.0", "", false, false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.other, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			equal, err := v.EqualString(tt.other)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantEqual, equal)
+
+			greater, err := v.GreaterThanString(tt.other)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantGreater, greater)
+
+			less, err := v.LessThanString(tt.other)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantLess, less)
+		})
+	}
+}
+
+func TestVersion_CompareEpoch(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		// An epoch always dominates the release segment
+		{"1!1.0", "999.0", 1},
+		{"1!1.0", "2.0", 1},
+		{"1!0.0.dev0", "999.9.9.post9", 1},
+		{"2!1.0", "1!5.0", 1},
+		{"2!1.0", "1!5.0.0.0.1", 1},
+		{"1!1.0.0.0.1", "2!1.0", -1},
+		{"0!1.0", "1.0", 0},
+		{"00!1.0", "0!1.0.0", 0},
+
+		// Equal epochs fall through to the release segment
+		{"1!1.0", "1!2.0", -1},
+		{"1!1.0.1", "1!1.0", 1},
+		{"1!1.0", "1!1.0.0", 0},
+		{"1!1.0rc1", "1!1.0", -1},
+		{"1!1.0+local", "1!1.0", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.Compare(v2))
+			assert.Equal(t, -tt.want, v2.Compare(v1))
+			assert.Equal(t, tt.want == 0, v1.Equal(v2))
+		})
+	}
+}
+
+func TestVersion_EqualRelease(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want bool
+	}{
+		{"1.0", "1.0.0", true},
+		{"1.0", "1.0.0.0", true},
+		{"1", "1.0.0.0", true},
+		{"1.0rc1", "1.0.0", true},
+		{"1.0.post1", "1.0.dev1", true},
+		{"1.0+local", "1.0.0.0a1.post2.dev3+other", true},
+		{"1.0", "1.0.1", false},
+		{"1.0.0.1", "1.0", false},
+		{"1.1", "1.0", false},
+		{"1!1.0", "1.0", false},
+		{"1!1.0", "1!1.0.0rc1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.EqualRelease(v2))
+			assert.Equal(t, tt.want, v2.EqualRelease(v1))
+		})
+	}
+}
+
+func TestVersion_SameReleaseIgnoringEpoch(t *testing.T) {
+	tests := []struct {
+		v1          string
+		v2          string
+		want        bool
+		wantRelease bool
+	}{
+		{"1!2.0", "2.0", true, false},
+		{"1!2.0", "2!2.0.0rc1+local", true, false},
+		{"1!2.0", "1!2.0.0", true, true},
+		{"2.0", "2.0.post1", true, true},
+		{"1!2.0", "2.1", false, false},
+		{"2.0", "2.0.1", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.SameReleaseIgnoringEpoch(v2))
+			assert.Equal(t, tt.want, v2.SameReleaseIgnoringEpoch(v1))
+			assert.Equal(t, tt.wantRelease, v1.EqualRelease(v2))
+		})
+	}
+}
+
+func TestVersion_EqualAliases(t *testing.T) {
+	tests := [][2]string{
+		// Pre-release aliases
+		{"1.0alpha1", "1.0a1"},
+		{"1.0beta2", "1.0b2"},
+		{"1.0c3", "1.0rc3"},
+		{"1.0pre3", "1.0rc3"},
+		{"1.0preview3", "1.0rc3"},
+		{"1.0-ALPHA", "1.0a0"},
+		// Post-release aliases
+		{"1.0rev1", "1.0.post1"},
+		{"1.0r1", "1.0.post1"},
+		{"1.0-1", "1.0.post1"},
+		{"1.0.rev", "1.0.post0"},
+		// Combined with other segments
+		{"1!1.0alpha1.rev2.dev3+abc", "1!1.0a1.post2.dev3+abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt[0]+" = "+tt[1], func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt[0], tt[1])
+			assert.True(t, v1.Equal(v2))
+			assert.Equal(t, 0, v2.Compare(v1))
+		})
+	}
+}
+
+func TestVersion_PreReleaseAliases(t *testing.T) {
+	tests := []struct {
+		want    string
+		aliases []string
+	}{
+		{"1.0rc1", []string{"1.0c1", "1.0rc1", "1.0pre1", "1.0preview1", "1.0-PREVIEW.1", "1.0_pre_1", "1.0.c.1"}},
+		{"1.0rc0", []string{"1.0c", "1.0rc", "1.0pre", "1.0preview", "1.0.preview"}},
+		{"1.0rc12", []string{"1.0c12", "1.0rc12", "1.0pre12", "1.0preview12", "1.0preview012"}},
+		{"1.0a1", []string{"1.0a1", "1.0alpha1", "1.0.ALPHA-1"}},
+		{"1.0b1", []string{"1.0b1", "1.0beta1", "1.0-beta.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			want := version.MustParse(tt.want)
+			for _, alias := range tt.aliases {
+				v, err := version.Parse(alias)
+				require.NoError(t, err, alias)
+
+				assert.Equal(t, tt.want, v.String(), alias)
+				assert.True(t, v.Equal(want), alias)
+				assert.Equal(t, want.CanonicalKey(), v.CanonicalKey(), alias)
+			}
+		})
+	}
+}
+
+func TestVersion_LeadingZeros(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		equal   string
+	}{
+		{"1.01.0", "1.1.0", "1.1"},
+		{"01.001", "1.1", "1.1.0"},
+		{"1.00", "1.0", "1"},
+		{"007!1.0", "7!1.0", "7!1"},
+		{"1.0rc01.post002.dev0003", "1.0rc1.post2.dev3", "1rc1.post2.dev3"},
+		{" v1.010 ", "1.10", "1.10.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.String())
+			assert.Equal(t, tt.version, v.Original())
+			assert.True(t, v.Equal(version.MustParse(tt.equal)))
+		})
+	}
+
+	// The numbers are compared numerically rather than lexically.
+	v1, v2 := parseVersions(t, "1.01", "1.2")
+	assert.True(t, v1.LessThan(v2))
+	v1, v2 = parseVersions(t, "1.010", "1.9")
+	assert.True(t, v1.GreaterThan(v2))
+}
+
+func TestVersion_OrderingChain(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "post-release before the next release",
+			want: []string{"1.0", "1.0.post1", "1.1.dev1", "1.1rc1", "1.1"},
+		},
+		{
+			name: "every kind of segment",
+			want: []string{
+				"1.0.dev1", "1.0a1.dev1", "1.0a1", "1.0a1.post1.dev1", "1.0a1.post1", "1.0b1", "1.0rc1",
+				"1.0", "1.0+local", "1.0.post1.dev1", "1.0.post1", "1.0.post1+local", "1.0.1.dev1", "1.1",
+			},
+		},
+		{
+			name: "local versions",
+			want: []string{
+				"1.0", "1.0+abc", "1.0+abc.1", "1.0+abc.1.def", "1.0+abc.2", "1.0+abd",
+				"1.0+1", "1.0+1.abc", "1.0+1.1", "1.0+1.2", "1.0+2", "1.0+10",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vs []version.Version
+			for i := len(tt.want) - 1; i >= 0; i-- {
+				vs = append(vs, version.MustParse(tt.want[i]))
+			}
+			sort.Sort(version.SortedVersions(vs))
+
+			var got []string
+			for _, v := range vs {
+				got = append(got, v.String())
+			}
+			assert.Equal(t, tt.want, got)
+
+			for i := 1; i < len(vs); i++ {
+				assert.True(t, vs[i-1].LessThan(vs[i]), "%s < %s", vs[i-1], vs[i])
+			}
+		})
+	}
+}
+
+func TestVersion_CompareLocal(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		// Numeric segments sort after alphanumeric ones
+		{"1.0+1", "1.0+abc", 1},
+		{"1.0+abc1", "1.0+1", -1},
+		// A shorter local version sorts first if it is a prefix of the longer one
+		{"1.0+abc.1", "1.0+abc", 1},
+		{"1.0+1.2", "1.0+1", 1},
+		{"1.0+1", "1.0+1.abc", -1},
+		// Numeric segments are compared numerically, alphanumeric ones lexically
+		{"1.0+10", "1.0+9", 1},
+		{"1.0+01", "1.0+1", 0},
+		{"1.0+abc", "1.0+ABC", 0},
+		{"1.0+abc10", "1.0+abc9", -1},
+		// Any local version sorts after none
+		{"1.0+0", "1.0", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.Compare(v2))
+			assert.Equal(t, -tt.want, v2.Compare(v1))
+		})
+	}
+}
+
+func TestVersion_CompareSelf(t *testing.T) {
+	for _, s := range versions {
+		v1, v2 := parseVersions(t, s, s)
+		assert.Equal(t, 0, v1.Compare(v1), s)
+		assert.Equal(t, 0, v1.Compare(v2), s)
+	}
+
+	// Versions with the same string compare as equal, as the removed String() fast path assumed.
+	// The alternative spellings normalize to the same string, while the local versions keep their separators.
+	spellings := append([]string{
+		"1.0alpha1", "1.0-a1", "1.0.a.1", "1.0BETA2", "1.0pre1", "1.0preview1", "1.0-c1", "1.0c.1",
+		"1.0-post456", "1.0.rev456", "1.0r456", "1.0-456", "1.0.post456.dev-34", "v1.0", "1.0.0",
+		"1.2+123-abc", "1.2+123_abc", "1.2+123.abc", "1.2+1234-abc", "1.2+1234_abc", "1.2+ABC",
+		"1.2.r32+123_456", "1!1.2+abc-123",
+	}, versions...)
+	parsed := make([]version.Version, len(spellings))
+	for i, s := range spellings {
+		parsed[i] = version.MustParse(s)
+	}
+	for i, v1 := range parsed {
+		for _, v2 := range parsed[i:] {
+			if v1.String() == v2.String() {
+				assert.Equal(t, 0, v1.Compare(v2), "%s %s", spellings[i], v2.Original())
+				assert.Equal(t, 0, v2.Compare(v1), "%s %s", v2.Original(), spellings[i])
+			}
+		}
+	}
+
+	var zero version.Version
+	assert.Equal(t, 0, zero.Compare(version.Version{}))
+	assert.Equal(t, -1, zero.Compare(version.MustParse("0.dev0")))
+	assert.Equal(t, 1, version.MustParse("0.dev0").Compare(zero))
+}
+
+func BenchmarkVersion_Compare(b *testing.B) {
+	benchmarks := [][2]string{
+		{"1.2.3", "1.2.3"},
+		{"1.2.3", "1.2.4"},
+		{"1!1.0alpha1.rev2.dev3+abc", "1!1.0a1.post2.dev3+abc"},
+	}
+	for _, bb := range benchmarks {
+		v1, v2 := version.MustParse(bb[0]), version.MustParse(bb[1])
+		b.Run(bb[0]+" "+bb[1], func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v1.Compare(v2)
+			}
+		})
+	}
+}
+
 func TestVersion_GreaterThan(t *testing.T) {
 	var tests [][2]string
 	for i, v1 := range versions {
@@ -328,3 +936,1092 @@ func parseVersions(t *testing.T, s1, s2 string) (version.Version, version.Versio
 
 	return v1, v2
 }
+
+func TestVersion_Components(t *testing.T) {
+	tests := []struct {
+		version string
+		want    version.Components
+	}{
+		{
+			version: "1.2",
+			want: version.Components{
+				Release: []int{1, 2},
+			},
+		},
+		{
+			version: "2!1.0alpha3.rev4.dev5+ubuntu-1_abc.2",
+			want: version.Components{
+				Epoch:      2,
+				Release:    []int{1, 0},
+				HasPre:     true,
+				PreLabel:   "a",
+				PreNumber:  3,
+				HasPost:    true,
+				PostNumber: 4,
+				HasDev:     true,
+				DevNumber:  5,
+				HasLocal:   true,
+				Local:      []string{"ubuntu", "1", "abc", "2"},
+			},
+		},
+		{
+			version: "1.0-0",
+			want: version.Components{
+				Release:    []int{1, 0},
+				HasPost:    true,
+				PostNumber: 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.Components())
+		})
+	}
+}
+
+func TestVersion_AsMap(t *testing.T) {
+	tests := []struct {
+		version string
+		want    map[string]interface{}
+	}{
+		{
+			version: "1.2",
+			want: map[string]interface{}{
+				"epoch":         0,
+				"release":       []int{1, 2},
+				"pre":           "",
+				"post":          nil,
+				"dev":           nil,
+				"local":         "",
+				"is_prerelease": false,
+			},
+		},
+		{
+			version: "2!1.0alpha3.rev4.dev5+ubuntu-1",
+			want: map[string]interface{}{
+				"epoch":         2,
+				"release":       []int{1, 0},
+				"pre":           "a3",
+				"post":          4,
+				"dev":           5,
+				"local":         "ubuntu-1",
+				"is_prerelease": true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.AsMap())
+		})
+	}
+}
+
+func TestVersion_Truncate(t *testing.T) {
+	tests := []struct {
+		version string
+		n       int
+		want    string
+	}{
+		{"1.4.7.post2", 2, "1.4"},
+		{"1.4.7", 3, "1.4.7"},
+		{"1.4", 4, "1.4.0.0"},
+		{"2!1.4.7rc1.dev3+local", 1, "2!1"},
+		{"1.4.7", 0, "1"},
+		{"1.4.7", -1, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got := v.Truncate(tt.n)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+		})
+	}
+}
+
+func TestCompareStrings(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{"1.0", "1.0.0", 0, false},
+		{"1.0a1", "1.0", -1, false},
+		{"1!1.0", "2.0", 1, false},
+		{"french toast", "1.0", 0, true},
+		{"1.0", "1.0++", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got, err := version.CompareStrings(tt.a, tt.b)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func ExampleCompare() {
+	vs := []version.Version{
+		version.MustParse("2.0"),
+		version.MustParse("1.0.post1"),
+		version.MustParse("1.0rc1"),
+		version.MustParse("1!0.1"),
+	}
+
+	// With Go 1.21 or later, this is slices.SortFunc(vs, version.Compare).
+	sort.Slice(vs, func(i, j int) bool {
+		return version.Compare(vs[i], vs[j]) < 0
+	})
+	fmt.Println(vs)
+	// Output: [1.0rc1 1.0.post1 2.0 1!0.1]
+}
+
+func TestLessStrings(t *testing.T) {
+	got := []string{"2.0", "french toast", "1.0", "1.0a1", "1.0++", "1!0.1"}
+	sort.Slice(got, func(i, j int) bool {
+		return version.LessStrings(got[i], got[j])
+	})
+	assert.Equal(t, []string{"1.0++", "french toast", "1.0a1", "1.0", "2.0", "1!0.1"}, got)
+}
+
+func TestVersion_Bump(t *testing.T) {
+	tests := []struct {
+		version string
+		bump    func(version.Version) (version.Version, error)
+		want    string
+		wantErr bool
+	}{
+		{"1.0rc1", version.Version.BumpPre, "1.0rc2", false},
+		{"1.0alpha", version.Version.BumpPre, "1.0a1", false},
+		{"1!1.0b9.post1.dev2+local", version.Version.BumpPre, "1!1.0b10", false},
+		{"1.0", version.Version.BumpPre, "", true},
+		{"1.0.post1", version.Version.BumpPost, "1.0.post2", false},
+		{"1.0-1", version.Version.BumpPost, "1.0.post2", false},
+		{"1.0rc1.post1.dev1", version.Version.BumpPost, "1.0rc1.post2", false},
+		{"1.0rc1", version.Version.BumpPost, "", true},
+		{"1.0.dev", version.Version.BumpDev, "1.0.dev1", false},
+		{"1.0a1.post2.dev3+local", version.Version.BumpDev, "1.0a1.post2.dev4", false},
+		{"1.0", version.Version.BumpDev, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, err := tt.bump(v)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.GreaterThan(v))
+		})
+	}
+}
+
+func TestVersion_Increment(t *testing.T) {
+	tests := []struct {
+		version string
+		i       int
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", 0, "2.0.0", false},
+		{"1.2.3", 1, "1.3.0", false},
+		{"1.2.3", 2, "1.2.4", false},
+		{"1.2", 2, "1.2.1", false},
+		{"1.2", 4, "1.2.0.0.1", false},
+		{"1!1.2.3rc1.post2.dev3+local", 1, "1!1.3.0", false},
+		{"1.9.9", 1, "1.10.0", false},
+		{"1.2.3", -1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.version, tt.i), func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, err := v.Increment(tt.i)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.GreaterThan(v))
+		})
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"2.0.0.0", "2.0.0.0", false},
+		{"v1.2.3-beta", "1.2.3b0", false},
+		{"1.2.3.4.5", "1.2.3.4.5", false},
+		{"2020.01.01", "2020.1.1", false},
+		{"1.2.3-foo+bar baz", "1.2.3", false},
+		{"release-1.2", "1.2", false},
+		{"Release_1.2rc1", "1.2rc1", false},
+		{"version-2.0.post1", "2.0.post1", false},
+		{" 1.2.3 (final) ", "1.2.3", false},
+		{"1.2.", "1.2", false},
+		{"french toast", "", true},
+		{"release-", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := version.Coerce(tt.version)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.version, got.Original())
+		})
+	}
+}
+
+func TestVersion_ReleaseKind(t *testing.T) {
+	tests := []struct {
+		version string
+		want    version.ReleaseKind
+	}{
+		{"1.0", version.Final},
+		{"1.0+local", version.Final},
+		{"1.0rc1", version.PreRelease},
+		{"1.0rc1.post2", version.PreRelease},
+		{"1.0.post1", version.PostRelease},
+		{"1.0-1", version.PostRelease},
+		{"1.0.dev1", version.DevRelease},
+		{"1.0.post1.dev1", version.DevRelease},
+		{"1.0rc1.post1.dev2", version.DevRelease},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.ReleaseKind())
+		})
+	}
+}
+
+func TestVersion_PreReleaseWeight(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"1.0", 3},
+		{"1.0+local", 3},
+		{"1.0.post1", 3},
+		{"1.0rc1", 2},
+		{"1.0c1", 2},
+		{"1.0preview1.post1", 2},
+		{"1.0rc1.dev1", 2},
+		{"1.0b1", 1},
+		{"1.0beta2.dev1", 1},
+		{"1.0a1", 0},
+		{"1.0alpha1.post1", 0},
+		{"1.0.dev1", -1},
+		{"1.0.post1.dev1", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.PreReleaseWeight())
+		})
+	}
+}
+
+func TestVersion_IsFinal(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"1.0", true},
+		{"1!2.0.0", true},
+		{"1.0+local", false},
+		{"1.0rc1", false},
+		{"1.0.post1", false},
+		{"1.0.dev1", false},
+		{"1.0rc1.post1", false},
+		{"1.0.post1.dev1", false},
+		{"1.0rc1.post1.dev1+local", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, v.IsFinal())
+		})
+	}
+}
+
+func TestVersion_WithEpoch(t *testing.T) {
+	tests := []struct {
+		version string
+		epoch   int
+		want    string
+		wantErr bool
+	}{
+		{"2.0", 1, "1!2.0", false},
+		{"3!2.0", 0, "2.0", false},
+		{"2.0rc1.post2.dev3+local", 5, "5!2.0rc1.post2.dev3+local", false},
+		{"2.0", -1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, err := v.WithEpoch(tt.epoch)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+		})
+	}
+}
+
+func TestVersion_WithRelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		segments []int
+		want     string
+		wantErr  bool
+	}{
+		{"1!2.0rc1", []int{3, 1}, "1!3.1", false},
+		{"1.2.3.post1.dev2+local", []int{4}, "4", false},
+		{"1.0", []int{0, 0, 0}, "0.0.0", false},
+		{"1.0", nil, "", true},
+		{"1.0", []int{1, -1}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.version, tt.segments), func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, err := v.WithRelease(tt.segments...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+		})
+	}
+}
+
+func TestVersion_WithPre(t *testing.T) {
+	tests := []struct {
+		version string
+		letter  string
+		n       int
+		want    string
+		wantErr bool
+	}{
+		{"1.0", "rc", 1, "1.0rc1", false},
+		{"1.0", "alpha", 0, "1.0a0", false},
+		{"1.0", "Preview", 2, "1.0rc2", false},
+		{"1.0b1.post2.dev3+local", "a", 4, "1.0a4.post2.dev3+local", false},
+		{"1.0b1.dev3", "", -1, "1.0.dev3", false},
+		{"1.0", "gamma", 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, err := v.WithPre(tt.letter, tt.n)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+		})
+	}
+}
+
+func TestVersion_WithPost_WithDev(t *testing.T) {
+	tests := []struct {
+		version  string
+		n        int
+		wantPost string
+		wantDev  string
+	}{
+		{"1.0", 0, "1.0.post0", "1.0.dev0"},
+		{"1.0rc1+local", 2, "1.0rc1.post2+local", "1.0rc1.dev2+local"},
+		{"1.0.post1.dev1", 3, "1.0.post3.dev1", "1.0.post1.dev3"},
+		{"1.0.post1.dev1", -1, "1.0.dev1", "1.0.post1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			post := v.WithPost(tt.n)
+			assert.Equal(t, tt.wantPost, post.String())
+			assert.True(t, post.Equal(version.MustParse(tt.wantPost)))
+
+			dev := v.WithDev(tt.n)
+			assert.Equal(t, tt.wantDev, dev.String())
+			assert.True(t, dev.Equal(version.MustParse(tt.wantDev)))
+		})
+	}
+}
+
+func TestVersion_WithoutDev(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.0rc1.dev3", "1.0rc1"},
+		{"1!1.0b2.post1.dev3+local", "1!1.0b2.post1+local"},
+		{"1.0.dev0", "1.0"},
+		{"1.0rc1", "1.0rc1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got := v.WithoutDev()
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+			assert.True(t, v.LessThanOrEqual(got))
+		})
+	}
+
+	v1, v2 := parseVersions(t, "1.0rc1.dev3", "1.0rc1")
+	assert.True(t, v1.LessThan(v1.WithoutDev()))
+	assert.True(t, v1.WithoutDev().Equal(v2))
+}
+
+func TestVersion_ToStable(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.2.0rc3", "1.2.0"},
+		{"1.2.0.dev5", "1.2.0"},
+		{"1.2.0rc3.dev5", "1.2.0"},
+		{"1.2.0a1+local", "1.2.0"},
+		{"1!1.2.0b1", "1!1.2.0"},
+		{"1.2.0", "1.2.0"},
+		{"1.2.0.post1.dev2", "1.2.0.post1"},
+		{"1.2.0rc3.post1", "1.2.0.post1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got := v.ToStable()
+			assert.Equal(t, tt.want, got.String())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+			assert.False(t, got.IsPreRelease())
+		})
+	}
+}
+
+func TestVersion_ReleaseSegment(t *testing.T) {
+	tests := []struct {
+		version string
+		i       int
+		want    int
+		wantOk  bool
+	}{
+		{"1.2.3", 0, 1, true},
+		{"1.2.3", 2, 3, true},
+		{"1.2.3", 3, 0, false},
+		{"1.2.3", 5, 0, false},
+		{"1.2.3", -1, 0, false},
+		{"2!1.02rc1", 1, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, ok := v.ReleaseSegment(tt.i)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestVersion_ReleaseString(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"2.3.4", "2.3.4"},
+		{"1!2.3.4", "2.3.4"},
+		{"v02.03.004rc1.post2.dev3+Local", "2.3.4"},
+		{"1!1.0.0", "1.0.0"},
+		{"99999999999999999999.1", "99999999999999999999.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.ReleaseString())
+		})
+	}
+
+	assert.Equal(t, "", version.Version{}.ReleaseString())
+}
+
+func TestVersion_BigNumbers(t *testing.T) {
+	// math.MaxInt64 is 9223372036854775807.
+	const huge = "9223372036854775808"
+
+	tests := []struct {
+		name    string
+		version string
+		want    string
+		lesser  string
+	}{
+		{"release", huge + ".1", huge + ".1", "9223372036854775807.1"},
+		{"date-based release", "20231231.235959", "20231231.235959", "20231231.99999"},
+		{"epoch", huge + "!1.0", huge + "!1.0", "99!1.0"},
+		{"pre-release", "1.0rc" + huge, "1.0rc" + huge, "1.0rc9223372036854775807"},
+		{"post-release", "1.0.post" + huge, "1.0.post" + huge, "1.0.post10"},
+		{"development release", "1.0.dev" + huge, "1.0.dev" + huge, "1.0.dev9"},
+		{"leading zeros", "0" + huge + ".01", huge + ".1", "9.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.String())
+
+			// The comparison must be numeric, e.g. "9" is lexically greater than "9223372036854775808".
+			lesser := version.MustParse(tt.lesser)
+			assert.True(t, v.GreaterThan(lesser), tt.lesser)
+			assert.True(t, lesser.LessThan(v), tt.lesser)
+			assert.True(t, v.Equal(version.MustParse(tt.want)))
+		})
+	}
+
+	t.Run("ReleaseBig", func(t *testing.T) {
+		v := version.MustParse("1!" + huge + ".02.3")
+		var got []string
+		for _, n := range v.ReleaseBig() {
+			got = append(got, n.String())
+		}
+		assert.Equal(t, []string{huge, "2", "3"}, got)
+		assert.Empty(t, version.Version{}.ReleaseBig())
+	})
+}
+
+func TestVersion_PreReleaseLabel(t *testing.T) {
+	tests := []struct {
+		version    string
+		wantLabel  string
+		wantNumber int
+		wantOk     bool
+	}{
+		{"1.0a1", "a", 1, true},
+		{"1.0alpha1", "a", 1, true},
+		{"1.0.beta.3", "b", 3, true},
+		{"1.0c2", "rc", 2, true},
+		{"1.0preview2", "rc", 2, true},
+		{"1.0RC", "rc", 0, true},
+		{"1.0rc1.post1.dev2", "rc", 1, true},
+		{"1.0", "", 0, false},
+		{"1.0.dev1", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			label, ok := v.PreReleaseLabel()
+			assert.Equal(t, tt.wantLabel, label)
+			assert.Equal(t, tt.wantOk, ok)
+
+			number, ok := v.PreReleaseNumber()
+			assert.Equal(t, tt.wantNumber, number)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestVersion_PostReleaseNumber(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+		wantOk  bool
+	}{
+		{"1.0-1", 1, true},
+		{"1.0.post1", 1, true},
+		{"1.0post1", 1, true},
+		{"1.0_rev1", 1, true},
+		{"1.0-r-1", 1, true},
+		{"1.0rc1-2.dev3", 2, true},
+		{"1.0.post", 0, true},
+		{"1.0", 0, false},
+		{"1.0rc1.dev1", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got, ok := v.PostReleaseNumber()
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+
+	// The implicit and explicit forms can't be distinguished.
+	implicit, explicit := version.MustParse("1.0-1"), version.MustParse("1.0.post1")
+	assert.Equal(t, explicit.String(), implicit.String())
+	assert.Equal(t, explicit.Components(), implicit.Components())
+}
+
+func TestVersion_CanonicalKey(t *testing.T) {
+	tests := []struct {
+		versions []string
+		want     string
+	}{
+		{[]string{"1.0alpha1", "1.0a1", "1.0.0a1", "v1.0-A1"}, "1a1"},
+		{[]string{"1.0rev1", "1.0.post1", "1-1"}, "1.post1"},
+		{[]string{"1.0c1", "1.0rc1", "1.0pre1", "1.0preview1"}, "1rc1"},
+		{[]string{"0", "0.0", "0.0.0"}, "0"},
+		{[]string{"1.2.0.3", "1.2.0.3.0"}, "1.2.0.3"},
+		{[]string{"1!2.0.dev0", "1!2.dev"}, "1!2.dev0"},
+		{[]string{"2.0+deadbeef.0", "2.0.0+deadbeef.00", "2+DeadBeef.000"}, "2+deadbeef.0"},
+		{[]string{"1.0+ubuntu-01", "1.0+ubuntu_1", "1.0+ubuntu.1"}, "1+ubuntu.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			keys := map[string]struct{}{}
+			for _, s := range tt.versions {
+				v, err := version.Parse(s)
+				require.NoError(t, err)
+
+				keys[v.CanonicalKey()] = struct{}{}
+				assert.True(t, v.Equal(version.MustParse(tt.versions[0])))
+			}
+			assert.Equal(t, map[string]struct{}{tt.want: {}}, keys)
+		})
+	}
+
+	// Every pair of versions must be equal if and only if they have the same key
+	for _, s1 := range versions {
+		for _, s2 := range versions {
+			v1, v2 := parseVersions(t, s1, s2)
+			assert.Equal(t, v1.Equal(v2), v1.CanonicalKey() == v2.CanonicalKey(), s1+" "+s2)
+		}
+	}
+}
+
+func TestVersion_IsCompatibleWith(t *testing.T) {
+	tests := []struct {
+		version string
+		base    string
+		want    bool
+	}{
+		{"2.2", "2.2", true},
+		{"2.5", "2.2", true},
+		{"2.2.1", "2.2", true},
+		{"3.0", "2.2", false},
+		{"2.1", "2.2", false},
+		{"2.2.5", "2.2.0", true},
+		{"2.3.0", "2.2.0", false},
+		{"1!2.5", "1!2.2", true},
+		{"2.5", "1!2.2", false},
+		{"2.2", "2", false},
+		{"2.2", "2.2+local", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" ~= "+tt.base, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.version, tt.base)
+			assert.Equal(t, tt.want, v1.IsCompatibleWith(v2))
+		})
+	}
+}
+
+func TestParseDetailed(t *testing.T) {
+	tests := []struct {
+		version string
+		want    map[string]string
+	}{
+		{
+			version: "v2!1.02-Alpha.1_rev-3.DEV4+Ubuntu-1",
+			want: map[string]string{
+				"epoch":   "2",
+				"release": "1.02",
+				"pre":     "-Alpha.1",
+				"pre_l":   "Alpha",
+				"pre_n":   "1",
+				"post":    "_rev-3",
+				"post_l":  "rev",
+				"post_n2": "3",
+				"dev":     ".DEV4",
+				"dev_l":   "DEV",
+				"dev_n":   "4",
+				"local":   "Ubuntu-1",
+			},
+		},
+		{
+			version: "1.0-1",
+			want: map[string]string{
+				"release": "1.0",
+				"post":    "-1",
+				"post_n1": "1",
+			},
+		},
+		{
+			version: "1.0",
+			want:    map[string]string{"release": "1.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, groups, err := version.ParseDetailed(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, groups)
+			assert.True(t, v.Equal(version.MustParse(tt.version)))
+		})
+	}
+
+	_, groups, err := version.ParseDetailed("french toast")
+	assert.Error(t, err)
+	assert.Nil(t, groups)
+}
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.0+abc", "1.0+abc", false},
+		{"1.0+ubuntu-1.2", "1.0+ubuntu-1.2", false},
+		{"1.0RC1", "1.0rc1", false},
+		{"1.0", "1.0", false},
+		{"1.0+ABC", "", true},
+		{"1.0+abc.Def", "", true},
+		{"1.0+", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := version.ParseStrict(tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+
+	t.Run("Parse lowercases the local version", func(t *testing.T) {
+		v, err := version.Parse("1.0+ABC")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0+abc", v.String())
+	})
+}
+
+func TestParse_CaseFolding(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.0RC1", "1.0rc1", false},
+		{"1.0Rc1", "1.0rc1", false},
+		{"1.0ALPHA1", "1.0a1", false},
+		{"1.0PREVIEW1", "1.0rc1", false},
+		{"1.0.POST1", "1.0.post1", false},
+		{"1.0REV1", "1.0.post1", false},
+		{"1.0.DEV1", "1.0.dev1", false},
+		{"1.0+ABC.Iii", "1.0+abc.iii", false},
+		{"V1.0", "1.0", false},
+
+		// Non-ASCII letters are rejected even if they are case-folded to ASCII letters.
+		{"1.0.poſt1", "", true}, // long s
+		{"1.0+K", "", true},     // Kelvin sign
+		{"1.0+İ", "", true},     // Turkish dotted capital I
+		{"1.0+ı", "", true},     // Turkish dotless small i
+		{"1.0rç1", "", true},
+		{"١.٠", "", true}, // Arabic-Indic digits
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, !tt.wantErr, version.ValidVersion(tt.version))
+
+			got, err := version.Parse(tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     string
+		wantRest string
+		wantErr  bool
+	}{
+		{"1.2.3-linux.tar.gz", "1.2.3", "-linux.tar.gz", false},
+		{"1.2.3.tar.gz", "1.2.3", ".tar.gz", false},
+		{"1.2.3-py3-none-any.whl", "1.2.3", "-py3-none-any.whl", false},
+		{"2.0rc1-cp39-cp39-manylinux_x86_64.whl", "2.0rc1", "-cp39-cp39-manylinux_x86_64.whl", false},
+		{"1.0.post1.dev2+local.1_foo bar", "1.0.post1.dev2+local.1_foo", " bar", false},
+		{"1!1.0-1_amd64", "1!1.0.post1", "_amd64", false},
+		{"v1.0", "1.0", "", false},
+		{"pkg-1.2.3", "", "", true},
+		{"", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, rest, err := version.ParsePrefix(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.String())
+			assert.Equal(t, tt.wantRest, rest)
+		})
+	}
+}
+
+func TestVersion_ComparePublic(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		{"1.0+build1", "1.0+build2", 0},
+		{"1.0+build1", "1.0", 0},
+		{"1.0.0+abc", "1.0+def", 0},
+		{"1.0+build1", "1.1+build1", -1},
+		{"1.0rc1+build2", "1.0+build1", -1},
+		{"1.0.post1+build1", "1.0+build2", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.ComparePublic(v2))
+			assert.Equal(t, -tt.want, v2.ComparePublic(v1))
+		})
+	}
+
+	// The zero value is less than any parsed version, as in Compare.
+	v := version.MustParse("1.0+build1")
+	assert.Equal(t, -1, version.Version{}.ComparePublic(v))
+	assert.Equal(t, 1, v.ComparePublic(version.Version{}))
+	assert.Equal(t, 0, version.Version{}.ComparePublic(version.Version{}))
+}
+
+func TestVersion_WithMeta(t *testing.T) {
+	v := version.MustParse("1.0")
+	yanked := v.WithMeta("yanked", true).WithMeta("upload_time", "2023-01-02")
+
+	got, ok := yanked.Meta("yanked")
+	require.True(t, ok)
+	assert.Equal(t, true, got)
+	got, ok = yanked.Meta("upload_time")
+	require.True(t, ok)
+	assert.Equal(t, "2023-01-02", got)
+	_, ok = yanked.Meta("unknown")
+	assert.False(t, ok)
+
+	// The original version is not modified.
+	_, ok = v.Meta("yanked")
+	assert.False(t, ok)
+
+	// The metadata is ignored by comparison and formatting.
+	assert.True(t, yanked.Equal(v))
+	assert.Equal(t, 0, yanked.Compare(version.MustParse("1.0.0")))
+	assert.True(t, yanked.LessThan(version.MustParse("1.1")))
+	assert.Equal(t, v.String(), yanked.String())
+	assert.Equal(t, v.CanonicalKey(), yanked.CanonicalKey())
+
+	// Clone keeps the metadata, and the copies are independent.
+	c := yanked.Clone()
+	got, ok = c.Meta("yanked")
+	require.True(t, ok)
+	assert.Equal(t, true, got)
+
+	c = c.WithMeta("yanked", false)
+	got, _ = yanked.Meta("yanked")
+	assert.Equal(t, true, got)
+	got, _ = c.Meta("yanked")
+	assert.Equal(t, false, got)
+}
+
+func TestVersion_LocalOnlyDiffers(t *testing.T) {
+	tests := []struct {
+		v1   string
+		v2   string
+		want bool
+	}{
+		{"1.0+build1", "1.0+build2", true},
+		{"1.0+build1", "1.0.0+build2", true},
+		{"1.0+build1", "1.0", true},
+		{"1.0+build1", "1.0+build1", false},
+		{"1.0+ubuntu-1", "1.0+ubuntu.1", false},
+		{"1.0", "1.0.0", false},
+		{"1.0+build1", "1.1+build2", false},
+		{"1.0+build1", "1.1+build1", false},
+		{"1.0rc1+build1", "1.0+build2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.LocalOnlyDiffers(v2))
+			assert.Equal(t, tt.want, v2.LocalOnlyDiffers(v1))
+		})
+	}
+
+	assert.False(t, version.Version{}.LocalOnlyDiffers(version.MustParse("1.0+build1")))
+}
+
+func TestVersion_LocalSegments(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{"1.0+ubuntu.1.2", []string{"ubuntu", "1", "2"}},
+		{"1.0+Ubuntu-1_2", []string{"ubuntu", "1", "2"}},
+		{"1.0+abc", []string{"abc"}},
+		{"1.0", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, v.LocalSegments())
+		})
+	}
+
+	// The separators are equivalent in comparison as well.
+	compareTests := []struct {
+		v1   string
+		v2   string
+		want int
+	}{
+		{"1.0+ubuntu.1.2", "1.0+ubuntu-1_2", 0},
+		{"1.0+ubuntu-1", "1.0+ubuntu.1.0", -1},
+		{"1.0+ubuntu_2", "1.0+ubuntu.10", -1},
+		{"1.0+abc-1", "1.0+abc.def", 1},
+	}
+	for _, tt := range compareTests {
+		t.Run(tt.v1+" "+tt.v2, func(t *testing.T) {
+			v1, v2 := parseVersions(t, tt.v1, tt.v2)
+			assert.Equal(t, tt.want, v1.Compare(v2))
+			assert.Equal(t, -tt.want, v2.Compare(v1))
+		})
+	}
+}
+
+func TestVersion_PublicVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.0+ubuntu.1", "1.0"},
+		{"1!2.0rc1.post2.dev3+abc", "1!2.0rc1.post2.dev3"},
+		{"1.0", "1.0"},
+		{"v1.0-1+Ubuntu_1", "1.0.post1"},
+		{"V1.0", "1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := version.Parse(tt.version)
+			require.NoError(t, err)
+
+			got := v.PublicVersion()
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.want, got.Original())
+			assert.Equal(t, v.Public(), got.String())
+			assert.Empty(t, got.Local())
+			assert.True(t, got.Equal(version.MustParse(tt.want)))
+			assert.Equal(t, 0, got.ComparePublic(v))
+		})
+	}
+
+	// The local version is greater than the public version.
+	v := version.MustParse("1.0+local")
+	assert.True(t, v.PublicVersion().LessThan(v))
+	assert.True(t, v.PublicVersion().GreaterThan(version.MustParse("1.0rc1+local")))
+}
+
+func TestGroupByRelease(t *testing.T) {
+	vs, err := version.ParseList([]string{"1.0", "1.00.1", "1.1rc1", "2.0", "1!1.0", "1!1.2", "1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		n    int
+		want map[string][]string
+	}{
+		{
+			n: 1,
+			want: map[string][]string{
+				"1":   {"1.0", "1.0.1", "1.1rc1", "1"},
+				"2":   {"2.0"},
+				"1!1": {"1!1.0", "1!1.2"},
+			},
+		},
+		{
+			n: 2,
+			want: map[string][]string{
+				"1.0":   {"1.0", "1.0.1", "1"},
+				"1.1":   {"1.1rc1"},
+				"2.0":   {"2.0"},
+				"1!1.0": {"1!1.0"},
+				"1!1.2": {"1!1.2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			got := map[string][]string{}
+			for k, group := range version.GroupByRelease(vs, tt.n) {
+				for _, v := range group {
+					got[k] = append(got[k], v.String())
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package version
+
+import (
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Wildcard represents a version prefix such as "1.0.*".
+type Wildcard struct {
+	prefix string
+}
+
+// ParseWildcard parses the given wildcard and returns a new Wildcard.
+// The wildcard must end with ".*", or be "*" to match any version.
+// As with the "==" operator, the prefix must not contain a dev or local segment.
+func ParseWildcard(s string) (Wildcard, error) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return Wildcard{}, nil
+	}
+
+	if !strings.HasSuffix(s, ".*") {
+		return Wildcard{}, xerrors.Errorf("wildcard must end with .*: %s", s)
+	}
+	if err := validate("==", s); err != nil {
+		return Wildcard{}, xerrors.Errorf("invalid wildcard (%s): %w", s, err)
+	}
+	return Wildcard{prefix: MustParse(strings.TrimSuffix(s, ".*")).String() + ".*"}, nil
+}
+
+// Matches tests if the version matches the wildcard.
+// It gives the same result as the "==" operator with the wildcard.
+func (w Wildcard) Matches(v Version) bool {
+	if w.prefix == "" {
+		return true
+	}
+	return specifierEqual(v, specifier{version: w.prefix})
+}
+
+// String returns the string format of the wildcard
+func (w Wildcard) String() string {
+	if w.prefix == "" {
+		return "*"
+	}
+	return w.prefix
+}
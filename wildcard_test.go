@@ -0,0 +1,65 @@
+package version
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWildcard(t *testing.T) {
+	tests := []struct {
+		wildcard string
+		want     string
+		wantErr  bool
+	}{
+		{"1.0.*", "1.0.*", false},
+		{"1.04.*", "1.4.*", false},
+		{" 2!1.* ", "2!1.*", false},
+		{"1.0.post1.*", "1.0.post1.*", false},
+		{"*", "*", false},
+		{"1.0", "", true},
+		{"1.*.0", "", true},
+		{"1.0.dev1.*", "", true},
+		{"1.0+local.*", "", true},
+		{"foo.*", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wildcard, func(t *testing.T) {
+			got, err := ParseWildcard(tt.wildcard)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestWildcard_Matches(t *testing.T) {
+	tests := []struct {
+		wildcard string
+		version  string
+		want     bool
+	}{
+		{"1.0.*", "1.0", true},
+		{"1.0.*", "1.0.5", true},
+		{"1.0.*", "1.0rc1", true},
+		{"1.0.*", "1.0.5+local", true},
+		{"1.0.*", "1.1", false},
+		{"1.0.*", "1!1.0", false},
+		{"2!1.*", "2!1.9", true},
+		{"1.04.*", "1.4.2", true},
+		{"*", "1!0.1.dev1", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.version, tt.wildcard), func(t *testing.T) {
+			w, err := ParseWildcard(tt.wildcard)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, w.Matches(MustParse(tt.version)))
+		})
+	}
+}